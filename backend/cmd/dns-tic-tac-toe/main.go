@@ -3,7 +3,10 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	dnsgame "dns-tic-tac-toe/pkg/dns"
@@ -26,9 +29,31 @@ type Config struct {
 	SessionIDLength   int `env:"SESSION_ID_LENGTH" envDefault:"8"`
 	PlayerTokenLength int `env:"PLAYER_TOKEN_LENGTH" envDefault:"8"`
 
-	// Session Cleanup Configuration
-	SessionMaxAge          time.Duration `env:"SESSION_MAX_AGE" envDefault:"120s"`
-	SessionCleanupInterval time.Duration `env:"SESSION_CLEANUP_INTERVAL" envDefault:"120s"`
+	// Session Expiry Configuration (sessions expire after a join, move or board view goes this long without another;
+	// finished games get the shorter grace period)
+	SessionIdleTimeout     time.Duration `env:"SESSION_IDLE_TIMEOUT" envDefault:"300s"`
+	SessionFinishedGrace   time.Duration `env:"SESSION_FINISHED_GRACE" envDefault:"60s"`
+	SessionCleanupInterval time.Duration `env:"SESSION_CLEANUP_INTERVAL" envDefault:"30s"`
+	// Deprecated: SESSION_MAX_AGE is the former name of SESSION_IDLE_TIMEOUT, still honored when that is unset
+	SessionMaxAge time.Duration `env:"SESSION_MAX_AGE"`
+
+	// Session Storage Configuration (memory; file or log to keep sessions in SESSION_STORE_DIR across restarts;
+	// sessions are saved after every command that changes them, and every SESSION_SAVE_INTERVAL for the changes
+	// the server makes by itself, which the log store also logs as they happen)
+	SessionStore        string        `env:"SESSION_STORE" envDefault:"memory"`
+	SessionStoreDir     string        `env:"SESSION_STORE_DIR" envDefault:"sessions"`
+	SessionSaveInterval time.Duration `env:"SESSION_SAVE_INTERVAL" envDefault:"2s"`
+	SessionLogRotate    int           `env:"SESSION_LOG_ROTATE_AFTER" envDefault:"1000"`
+
+	// Turn Inactivity Configuration (a limit of 0 disables it)
+	TurnInactivityLimit  time.Duration `env:"TURN_INACTIVITY_LIMIT" envDefault:"0s"`
+	TurnInactivityAction string        `env:"TURN_INACTIVITY_ACTION" envDefault:"forfeit"`
+
+	// Crowd Play Configuration (default voting window of crowd-play sessions)
+	CrowdVotingWindow time.Duration `env:"CROWD_VOTING_WINDOW" envDefault:"30s"`
+
+	// Event Logging Configuration
+	LogGameEvents bool `env:"LOG_GAME_EVENTS" envDefault:"false"`
 }
 
 func main() {
@@ -37,6 +62,14 @@ func main() {
 	if err := env.Parse(&cfg); err != nil {
 		log.Fatalf("Failed to parse configuration: %v", err)
 	}
+	if cfg.SessionMaxAge > 0 {
+		if _, ok := os.LookupEnv("SESSION_IDLE_TIMEOUT"); ok {
+			log.Printf("SESSION_MAX_AGE is deprecated and ignored, as SESSION_IDLE_TIMEOUT is set")
+		} else {
+			log.Printf("SESSION_MAX_AGE is deprecated, use SESSION_IDLE_TIMEOUT instead")
+			cfg.SessionIdleTimeout = cfg.SessionMaxAge
+		}
+	}
 
 	// Normalize zone (ensure trailing dot)
 	zone := cfg.DNSZone
@@ -51,17 +84,87 @@ func main() {
 	}
 
 	// Create session manager with config using functional options
-	sessionManager := game.NewManager(
+	managerOpts := []game.ManagerOption{
 		game.WithSessionIDLength(cfg.SessionIDLength),
 		game.WithPlayerTokenLength(cfg.PlayerTokenLength),
-	)
+		game.WithVotingWindow(cfg.CrowdVotingWindow),
+		game.WithIdleTimeout(cfg.SessionIdleTimeout),
+		game.WithFinishedGracePeriod(cfg.SessionFinishedGrace),
+	}
+	if cfg.LogGameEvents {
+		managerOpts = append(managerOpts, game.WithHook(logEvent))
+	}
+	store, err := newSessionStore(cfg.SessionStore, cfg.SessionStoreDir, cfg.SessionLogRotate)
+	if err != nil {
+		log.Fatalf("Failed to create session store: %v", err)
+	}
+	managerOpts = append(managerOpts, game.WithStore(store))
+	sessionManager := game.NewManager(managerOpts...)
 
-	// Start session cleanup goroutine
+	// Bring back the sessions saved before the last restart
+	restored, err := sessionManager.RestoreSessions()
+	if err != nil {
+		log.Printf("Failed to restore some sessions: %v", err)
+	}
+	if restored > 0 {
+		log.Printf("Restored %d session(s) from %s", restored, cfg.SessionStoreDir)
+	}
+
+	// Start session expiry goroutine
 	go func() {
 		ticker := time.NewTicker(cfg.SessionCleanupInterval)
 		defer ticker.Stop()
 		for range ticker.C {
-			sessionManager.CleanupOldSessions(cfg.SessionMaxAge)
+			if err := sessionManager.ExpireIdleSessions(); err != nil {
+				log.Printf("Failed to expire sessions: %v", err)
+			}
+		}
+	}()
+
+	// Start session saving goroutine, writing the sessions that changed since they were last saved
+	go func() {
+		ticker := time.NewTicker(cfg.SessionSaveInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := sessionManager.SaveSessions(); err != nil {
+				log.Printf("Failed to save sessions: %v", err)
+			}
+		}
+	}()
+
+	// Save every session once more before exiting, e.g. on systemctl restart
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-signals
+		if err := sessionManager.SaveSessions(); err != nil {
+			log.Printf("Failed to save sessions: %v", err)
+		}
+		log.Printf("Received %s, shutting down", sig)
+		os.Exit(0)
+	}()
+
+	// Start turn inactivity goroutine, checking a few times per limit
+	if cfg.TurnInactivityLimit > 0 {
+		action, err := game.ParseInactivityAction(cfg.TurnInactivityAction)
+		if err != nil {
+			log.Fatalf("Failed to parse configuration: %v", err)
+		}
+		go func() {
+			ticker := time.NewTicker(max(cfg.TurnInactivityLimit/4, time.Second))
+			defer ticker.Stop()
+			for range ticker.C {
+				sessionManager.EnforceTurnLimit(cfg.TurnInactivityLimit, action)
+			}
+		}()
+	}
+
+	// Start crowd-play goroutine, playing the most-voted move of every voting round that has closed
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			sessionManager.CloseVotingRounds()
 		}
 	}()
 
@@ -138,3 +241,34 @@ func main() {
 		log.Fatalf("Failed to start TCP server: %v", err)
 	}
 }
+
+// newSessionStore creates the session store selected by kind: memory, file to save each session in dir,
+// or log to also write every change to an event log in dir, starting a new segment every rotateAfter records
+func newSessionStore(kind, dir string, rotateAfter int) (game.SessionStore, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "memory":
+		return game.NewMemoryStore(), nil
+	case "file":
+		return game.NewFileStore(dir)
+	case "log":
+		return game.NewLogStore(dir, rotateAfter)
+	default:
+		return nil, fmt.Errorf("invalid session store %q (expected memory, file or log)", kind)
+	}
+}
+
+// logEvent logs a game event on one line
+func logEvent(event game.Event) {
+	switch event.Type {
+	case game.EventMoveMade, game.EventCollapse:
+		log.Printf("session %s: %s: %s", event.SessionID, event.Type, event.Move)
+	case game.EventGameOver:
+		log.Printf("session %s: %s: %s", event.SessionID, event.Type, event.Status)
+	case game.EventPlayerJoined, game.EventFirstMove:
+		log.Printf("session %s: %s: %s", event.SessionID, event.Type, event.Player)
+	case game.EventTakeback:
+		log.Printf("session %s: %s: %s's last %d moves", event.SessionID, event.Type, event.Player, event.Undone)
+	default:
+		log.Printf("session %s: %s", event.SessionID, event.Type)
+	}
+}
@@ -2,11 +2,13 @@ module dns-tic-tac-toe
 
 go 1.21
 
-require github.com/miekg/dns v1.1.57
+require (
+	github.com/caarlos0/env/v11 v11.3.1
+	github.com/google/uuid v1.6.0
+	github.com/miekg/dns v1.1.57
+)
 
 require (
-	github.com/caarlos0/env/v11 v11.3.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	golang.org/x/mod v0.14.0 // indirect
 	golang.org/x/net v0.19.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
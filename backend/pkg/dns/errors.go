@@ -1,6 +1,10 @@
 package dns
 
-import "fmt"
+import (
+	"fmt"
+
+	"dns-tic-tac-toe/pkg/game"
+)
 
 // Error represents a DNS server-related error
 type Error struct {
@@ -22,6 +26,8 @@ const (
 	ErrCodeZoneMismatch      ErrorCode = "ZONE_MISMATCH"
 	ErrCodeSessionNotFound   ErrorCode = "SESSION_NOT_FOUND"
 	ErrCodeSessionCreate     ErrorCode = "SESSION_CREATE_FAILED"
+	ErrCodeInvalidOptions    ErrorCode = "INVALID_CREATE_OPTIONS"
+	ErrCodeUnsupported       ErrorCode = "UNSUPPORTED_COMMAND"
 )
 
 // Predefined errors
@@ -86,6 +92,70 @@ func NewInvalidMoveFormatError(format string) *Error {
 	}
 }
 
+// NewInvalidDropFormatError creates a new invalid drop format error
+func NewInvalidDropFormatError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidMoveFormat,
+		Message: fmt.Sprintf("invalid drop format: %s. Use: {session-id}-{token}-drop-COL (e.g., abc123-xyz78901-drop-3)", format),
+	}
+}
+
+// NewInvalidPlayFormatError creates a new invalid board-and-cell move format error
+func NewInvalidPlayFormatError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidMoveFormat,
+		Message: fmt.Sprintf("invalid play format: %s. Use: {session-id}-{token}-play-BOARD-CELL (e.g., abc123-xyz78901-play-4-0)", format),
+	}
+}
+
+// NewInvalidCollapseFormatError creates a new invalid collapse format error
+func NewInvalidCollapseFormatError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidMoveFormat,
+		Message: fmt.Sprintf("invalid collapse format: %s. Use: {session-id}-{token}-collapse-ROW-COL (e.g., abc123-xyz78901-collapse-2-2)", format),
+	}
+}
+
+// NewInvalidBotLevelError creates a new invalid join-bot format error
+func NewInvalidBotLevelError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidCommand,
+		Message: fmt.Sprintf("invalid bot level: %s. Use: {session-id}.join-bot-LEVEL with easy, medium or hard (e.g., abc123.join-bot-hard)", format),
+	}
+}
+
+// NewInvalidReplayFormatError creates a new invalid replay format error
+func NewInvalidReplayFormatError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidCommand,
+		Message: fmt.Sprintf("invalid replay format: %s. Use: {session-id}.replay-N with N from 0 (the starting position) to the number of moves (e.g., abc123.replay-3)", format),
+	}
+}
+
+// NewInvalidVoteFormatError creates a new invalid vote format error
+func NewInvalidVoteFormatError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidCommand,
+		Message: fmt.Sprintf("invalid vote format: %s. Use: {session-id}.vote-TEAM-ROW-COL with TEAM x or o (e.g., abc123.vote-x-1-1)", format),
+	}
+}
+
+// NewInvalidFirstMoveFormatError creates a new invalid first-move format error
+func NewInvalidFirstMoveFormatError(format string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidCommand,
+		Message: fmt.Sprintf("invalid first move: %s. Use: {session-id}-{token}-first-RULE with x, o, random, alternate or loser (e.g., abc123-xyz78901-first-random)", format),
+	}
+}
+
+// NewUnsupportedCommandError creates a new error for a command the session's game variant does not support
+func NewUnsupportedCommandError(command Command, variant game.Variant) *Error {
+	return &Error{
+		Code:    ErrCodeUnsupported,
+		Message: fmt.Sprintf("command %s is not supported in %s sessions", command, variant),
+	}
+}
+
 // NewInvalidPlayerError creates a new invalid player error
 func NewInvalidPlayerError(player string) *Error {
 	return &Error{
@@ -118,6 +188,14 @@ func NewSessionCreateError(err error) *Error {
 	}
 }
 
+// NewInvalidCreateOptionsError creates a new invalid session creation options error
+func NewInvalidCreateOptionsError(options string, reason string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidOptions,
+		Message: fmt.Sprintf("invalid session options: %s (%s)", options, reason),
+	}
+}
+
 // NewZoneMismatchError creates a new zone mismatch error
 func NewZoneMismatchError(queryZone, expectedZone string) *Error {
 	return &Error{
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"dns-tic-tac-toe/pkg/game"
 
@@ -35,6 +36,14 @@ func WriteSessionCreated(msg *dns.Msg, qname string, sessionID SessionID, ttl ui
 	writeText(msg, qname, response, ttl)
 }
 
+// WriteCrowdSessionCreated writes the response for a new crowd-play session, with voting examples
+func WriteCrowdSessionCreated(msg *dns.Msg, qname string, sessionID SessionID, ttl uint32, zone string) {
+	zoneExample := strings.TrimSuffix(zone, ".")
+	response := fmt.Sprintf("New crowd-play session created!\nSession ID: %s\n\nAnyone can vote for team X or O's next move; the most-voted move is played when voting closes:\n- %s.vote-x-1-1.%s\n- %s.votes.%s\n- %s.board.%s",
+		sessionID, sessionID, zoneExample, sessionID, zoneExample, sessionID, zoneExample)
+	writeText(msg, qname, response, ttl)
+}
+
 // WriteSessionList writes a list of active sessions
 func WriteSessionList(msg *dns.Msg, qname string, sessions []string, ttl uint32, zone string) {
 	zoneExample := strings.TrimSuffix(zone, ".")
@@ -46,31 +55,228 @@ func WriteSessionList(msg *dns.Msg, qname string, sessions []string, ttl uint32,
 	writeText(msg, qname, response, ttl)
 }
 
-// WriteBoard writes a board view response
-func WriteBoard(msg *dns.Msg, qname string, sessionID SessionID, gameEngine game.Engine, ttl uint32) {
-	response := fmt.Sprintf("Session: %s\n%s", sessionID, gameEngine.FormatBoard())
+// WriteBoard writes a board view response, as viewer sees it in games with hidden information ("" for spectators)
+func WriteBoard(msg *dns.Msg, qname string, sessionID SessionID, session *game.Session, viewer game.Player, ttl uint32) {
+	response := fmt.Sprintf("Session: %s\n%s", sessionID, session.FormatBoardFor(viewer))
 	writeText(msg, qname, response, ttl)
 }
 
 // WriteBoardWithMessage writes a board view with an additional message
-func WriteBoardWithMessage(msg *dns.Msg, qname string, sessionID SessionID, message string, gameEngine game.Engine, ttl uint32) {
-	response := fmt.Sprintf("Session: %s\n%s\n%s", sessionID, message, gameEngine.FormatBoard())
+func WriteBoardWithMessage(msg *dns.Msg, qname string, sessionID SessionID, message string, session *game.Session, viewer game.Player, ttl uint32) {
+	response := fmt.Sprintf("Session: %s\n%s\n%s", sessionID, message, session.FormatBoardFor(viewer))
 	writeText(msg, qname, response, ttl)
 }
 
 // WriteMoveAccepted writes a move acceptance response
-func WriteMoveAccepted(msg *dns.Msg, qname string, sessionID SessionID, gameEngine game.Engine, ttl uint32) {
-	WriteBoardWithMessage(msg, qname, sessionID, "Move accepted!", gameEngine, ttl)
+// botReply describes the bot's answering move, if the session has a bot that moved
+func WriteMoveAccepted(msg *dns.Msg, qname string, sessionID SessionID, botReply string, session *game.Session, viewer game.Player, ttl uint32) {
+	message := "Move accepted!"
+	if moves := session.StateFor(viewer).Moves; len(moves) > 0 && moves[len(moves)-1].Blocked {
+		last := moves[len(moves)-1]
+		message = fmt.Sprintf("Blocked! Your opponent has a hidden mark at %d-%d, your turn is over", last.Row, last.Col)
+	}
+	if botReply != "" {
+		message = fmt.Sprintf("%s\n%s", message, botReply)
+	}
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// WriteBotJoined writes a response for a bot taking a seat
+func WriteBotJoined(msg *dns.Msg, qname string, sessionID SessionID, bot *game.Bot, botReply string, session *game.Session, viewer game.Player, ttl uint32) {
+	message := fmt.Sprintf("Bot joined: %s", bot)
+	if botReply != "" {
+		message = fmt.Sprintf("%s\n%s", message, botReply)
+	}
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
 }
 
 // WriteMoveError writes a move error response
-func WriteMoveError(msg *dns.Msg, qname string, sessionID SessionID, err error, gameEngine game.Engine, ttl uint32) {
-	WriteBoardWithMessage(msg, qname, sessionID, fmt.Sprintf("ERROR: %s", err.Error()), gameEngine, ttl)
+func WriteMoveError(msg *dns.Msg, qname string, sessionID SessionID, err error, session *game.Session, viewer game.Player, ttl uint32) {
+	WriteBoardWithMessage(msg, qname, sessionID, fmt.Sprintf("ERROR: %s", err.Error()), session, viewer, ttl)
 }
 
 // WriteReset writes a game reset response
-func WriteReset(msg *dns.Msg, qname string, sessionID SessionID, gameEngine game.Engine, ttl uint32) {
-	WriteBoardWithMessage(msg, qname, sessionID, "Game reset!", gameEngine, ttl)
+func WriteReset(msg *dns.Msg, qname string, sessionID SessionID, session *game.Session, viewer game.Player, ttl uint32) {
+	WriteBoardWithMessage(msg, qname, sessionID, "Game reset!", session, viewer, ttl)
+}
+
+// WriteUndoRequested writes a response for a takeback request awaiting the opponent
+func WriteUndoRequested(msg *dns.Msg, qname string, sessionID SessionID, player game.Player, session *game.Session, viewer game.Player, ttl uint32) {
+	message := fmt.Sprintf("Takeback requested by %s, waiting for the opponent to accept", player)
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// WriteUndoAccepted writes a response for an accepted takeback
+func WriteUndoAccepted(msg *dns.Msg, qname string, sessionID SessionID, undone int, session *game.Session, viewer game.Player, ttl uint32) {
+	message := fmt.Sprintf("Takeback accepted: %d move(s) undone", undone)
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// WriteDrawOffered writes a response for a draw offer
+// bot is the bot opponent that answered the offer, nil when a human opponent has to answer
+func WriteDrawOffered(msg *dns.Msg, qname string, sessionID SessionID, player game.Player, bot *game.Bot, accepted bool, session *game.Session, viewer game.Player, ttl uint32) {
+	message := fmt.Sprintf("Draw offered by %s, waiting for the opponent to accept", player)
+	if bot != nil {
+		message = fmt.Sprintf("Draw offered by %s: %s declined", player, bot)
+		if accepted {
+			message = fmt.Sprintf("Draw offered by %s: %s accepted", player, bot)
+		}
+	}
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// WriteRematch writes a response for a rematch with swapped sides
+func WriteRematch(msg *dns.Msg, qname string, sessionID SessionID, player game.Player, botReply string, session *game.Session, viewer game.Player, ttl uint32) {
+	message := fmt.Sprintf("Rematch! Sides swapped, you now play %s", player)
+	if session.SeatCount() > 2 {
+		message = fmt.Sprintf("Rematch! Everyone moved on to the next symbol, you now play %s", player)
+	}
+	if botReply != "" {
+		message = fmt.Sprintf("%s\n%s", message, botReply)
+	}
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// WriteFirstMoveSet writes a response for a change of the first-move rule
+// botReply describes the bot's opening move, if the change put the bot on move
+func WriteFirstMoveSet(msg *dns.Msg, qname string, sessionID SessionID, botReply string, session *game.Session, viewer game.Player, ttl uint32) {
+	message := firstMoveLine(session)
+	if botReply != "" {
+		message = fmt.Sprintf("%s\n%s", message, botReply)
+	}
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// firstMoveLine describes who moves first in the current game, and the rule that decided it unless it is fixed
+func firstMoveLine(session *game.Session) string {
+	rule, first := session.FirstMove()
+	if rule == game.FirstMoveX || rule == game.FirstMoveO {
+		return fmt.Sprintf("First move: %s", first)
+	}
+	return fmt.Sprintf("First move: %s (%s)", first, rule)
+}
+
+// WriteHint writes the suggested move, in the variant's move syntax, and its expected outcome
+func WriteHint(msg *dns.Msg, qname string, sessionID SessionID, hint *game.Hint, session *game.Session, viewer game.Player, ttl uint32) {
+	message := fmt.Sprintf("Hint for %s: %s (expected result: %s)",
+		hint.Player, moveCommand(session.Game.GetState().Variant, hint.Move), hint.Outcome)
+	WriteBoardWithMessage(msg, qname, sessionID, message, session, viewer, ttl)
+}
+
+// moveCommand returns the command that plays a move in the variant's move syntax
+func moveCommand(variant game.Variant, move game.Position) string {
+	switch variant {
+	case game.VariantConnectFour:
+		return fmt.Sprintf("drop-%d", move.Col)
+	case game.VariantUltimate:
+		board, cell := game.UltimateBoardCell(move.Row, move.Col)
+		return fmt.Sprintf("play-%d-%d", board, cell)
+	case game.VariantNotakto:
+		board, cell := game.NotaktoBoardCell(move.Row, move.Col)
+		return fmt.Sprintf("play-%d-%d", board, cell)
+	default:
+		return fmt.Sprintf("move-%d-%d", move.Row, move.Col)
+	}
+}
+
+// WriteHistory writes the list of moves played so far, one per line
+func WriteHistory(msg *dns.Msg, qname string, sessionID SessionID, state *game.GameState, ttl uint32) {
+	if len(state.Moves) == 0 {
+		writeText(msg, qname, fmt.Sprintf("Session: %s\nNo moves yet | Status: %s", sessionID, state.Status), ttl)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Session: %s\nMoves (%d):\n", sessionID, len(state.Moves)))
+	for _, move := range state.Moves {
+		sb.WriteString(fmt.Sprintf("%s at %s", move, move.Timestamp.UTC().Format("15:04:05")))
+		if move.Reason != "" {
+			sb.WriteString(fmt.Sprintf(" (played by the server: %s)", move.Reason))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("Status: %s", state.Status))
+	writeText(msg, qname, sb.String(), ttl)
+}
+
+// WriteReplay writes the board after a replayed move, rendered like WriteBoard, preceded by the move that produced it
+func WriteReplay(msg *dns.Msg, qname string, sessionID SessionID, replay *game.Replay, ttl uint32) {
+	title := "Replay"
+	if replay.Previous {
+		title = "Replay of the previous game"
+	}
+	message := fmt.Sprintf("%s: start position (%d moves)", title, replay.Total)
+	if replay.Move != nil {
+		message = fmt.Sprintf("%s: move %d of %d: %s", title, replay.Number, replay.Total, replay.Move)
+	}
+	response := fmt.Sprintf("Session: %s\n%s\n%s", sessionID, message, replay.Board)
+	writeText(msg, qname, response, ttl)
+}
+
+// WriteVoteCounted writes the response to a crowd-play vote, with the round's tally so far
+func WriteVoteCounted(msg *dns.Msg, qname string, sessionID SessionID, vote *VoteParams, votes *game.CrowdVotes, ttl uint32) {
+	response := fmt.Sprintf("Session: %s\nVote counted: team %s to play %d-%d\n%s", sessionID, vote.Team, vote.Row, vote.Col, votes)
+	writeText(msg, qname, response, ttl)
+}
+
+// WriteVotes writes the tally of a crowd-play session's current voting round, followed by the board
+func WriteVotes(msg *dns.Msg, qname string, sessionID SessionID, votes *game.CrowdVotes, session *game.Session, ttl uint32) {
+	response := fmt.Sprintf("Session: %s\n%s\n%s", sessionID, votes, session.FormatBoard())
+	writeText(msg, qname, response, ttl)
+}
+
+// WriteExport writes the game in compact notation: the current position, the start position if the game
+// was set up from one, the moves played, and the command that recreates the game while it is undecided
+func WriteExport(msg *dns.Msg, qname string, sessionID SessionID, state *game.GameState, ttl uint32, zone string) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Session: %s\nPosition: %s\n", sessionID, game.FormatPosition(state)))
+	if state.Start != "" {
+		sb.WriteString(fmt.Sprintf("Start: %s\n", state.Start))
+	}
+	moves := "none"
+	if len(state.Moves) > 0 {
+		moves = game.FormatMoveList(state.Moves)
+	}
+	sb.WriteString(fmt.Sprintf("Moves: %s\n", moves))
+	// Quantum, Notakto, fog-of-war and multiplayer positions cannot be imported from compact notation
+	if !state.Status.IsOver() && state.Quantum == nil && state.Notakto == nil && state.Fog == nil && len(state.Players) <= 2 {
+		sb.WriteString(fmt.Sprintf("Import: %s.%s\n", importCommand(state), strings.TrimSuffix(zone, ".")))
+	}
+	sb.WriteString(fmt.Sprintf("Status: %s", state.Status))
+	writeText(msg, qname, sb.String(), ttl)
+}
+
+// importCommand returns the create command that sets up a new session at state's position
+// Games played from the empty board are imported by their move list so the history carries over
+func importCommand(state *game.GameState) string {
+	command := "new"
+	board := game.BoardConfig{Rows: state.Rows, Cols: state.Cols, WinLength: state.WinLength}
+	switch state.Variant {
+	case game.VariantConnectFour:
+		command += "-connect4"
+		if board != game.ConnectFourBoardConfig {
+			command += "-" + board.String()
+		}
+	case game.VariantUltimate:
+		command += "-ultimate"
+	default:
+		if board != game.DefaultBoardConfig {
+			command += "-" + board.String()
+		}
+	}
+	if state.Misere {
+		command += "-misere"
+	}
+
+	switch {
+	case state.Start == "" && len(state.Moves) > 0:
+		return command + "-from-" + strings.ReplaceAll(game.FormatMoveList(state.Moves), " ", "-")
+	case state.Start != "" || len(state.Moves) > 0:
+		position := strings.NewReplacer("/", "", ":", "-").Replace(game.FormatPosition(state))
+		return command + "-from-" + strings.ToLower(position)
+	default:
+		return command
+	}
 }
 
 // WriteJSON writes a JSON state response
@@ -80,17 +286,37 @@ func WriteJSON(msg *dns.Msg, qname string, gameEngine game.Engine, ttl uint32) {
 }
 
 // WriteJSONWithSession writes a JSON state response, adjusting status based on player count
-func WriteJSONWithSession(msg *dns.Msg, qname string, gameEngine game.Engine, session *game.Session, ttl uint32) {
-	state := gameEngine.GetState()
+// state is the session's state as the querying player or spectator sees it, see Session.StateFor
+func WriteJSONWithSession(msg *dns.Msg, qname string, state *game.GameState, session *game.Session, ttl uint32) {
 
-	// Status should only be "playing" when exactly 2 players have joined
-	// If less than 2 players, set status to pending (regardless of game engine status)
-	if session.GetPlayerCount() < 2 {
+	// Status should only be "playing" once every seat is taken
+	// Until then, set status to pending (regardless of game engine status)
+	if session.GetPlayerCount() < session.SeatCount() {
 		state.Status = game.StatusPending
 	}
-	// If 2 players have joined, use the game engine's status (playing, X_wins, O_wins, or draw)
+	// Once all players have joined, use the game engine's status (playing, X_wins, O_wins, or draw)
+
+	// Session-level fields are added next to the engine's state
+	var votes *game.CrowdVotes
+	if session.IsCrowd() {
+		votes, _ = session.Votes()
+	}
+	var expiresAt *time.Time
+	if at := session.ExpiresAt(); !at.IsZero() {
+		expiresAt = &at
+	}
+	jsonData, _ := json.Marshal(struct {
+		*game.GameState
+		Series    *game.Series     `json:"series,omitempty"`
+		Votes     *game.CrowdVotes `json:"votes,omitempty"`
+		ExpiresAt *time.Time       `json:"expires_at,omitempty"`
+	}{state, session.Series(), votes, expiresAt})
+	writeText(msg, qname, string(jsonData), ttl)
+}
 
-	jsonData, _ := json.Marshal(state)
+// WriteAnalysisJSON writes a position analysis as JSON
+func WriteAnalysisJSON(msg *dns.Msg, qname string, analysis *game.Analysis, ttl uint32) {
+	jsonData, _ := json.Marshal(analysis)
 	writeText(msg, qname, string(jsonData), ttl)
 }
 
@@ -100,22 +326,56 @@ func WriteHelp(msg *dns.Msg, qname string, ttl uint32, zone string) {
 	help := fmt.Sprintf(`DNS Tic-Tac-Toe Commands:
 
 Session Management:
-- new.%s - Create a new game session
-- list.%s - List all active sessions
+- new.%[1]s - Create a new game session (classic 3x3)
+- new-ROWSxCOLS-K.%[1]s - Create a session on a custom board, K in a row wins (e.g., new-15x15-5)
+- new-connect4.%[1]s - Create a Connect Four session (7x6, four in a row wins)
+- new-ultimate.%[1]s - Create an ultimate tic-tac-toe session (nine local boards)
+- new-quantum.%[1]s - Create a quantum tic-tac-toe session (each move marks two cells)
+- new-notakto-N.%[1]s - Create a Notakto session on N boards (default 3): both players place X, completing the last line loses
+- new-fog.%[1]s - Create a fog-of-war session: you only see your own marks and the ones you run into (combinable, e.g. new-fog-4x4-3)
+- new-misere.%[1]s - Play misère rules: completing a line loses (combinable, e.g. new-4x4-3-misere, new-connect4-misere)
+- new-ROWSxCOLS-K-players-N.%[1]s - Seat N players (2-4) who take turns as X, O, Δ and □; the game starts once all have joined (e.g. new-9x9-4-players-3, new-connect4-players-4)
+- new-ROWSxCOLS-K-players-N-symbols-CHARS.%[1]s - Seat the players after X and O with symbols of your own, one character each (e.g. new-9x9-4-players-4-symbols-ab for X, O, A and B)
+- new-crowd-SECONDS.%[1]s - Let the audience vote on both teams' moves; the most-voted move is played SECONDS after a round's first vote (default 30, e.g. new-crowd-20)
+- new-vs-ai-LEVEL.%[1]s - Create a session against a bot (easy, medium or hard), e.g. new-vs-ai-hard
+- new-blitz-SECONDS.%[1]s - Give each player a clock, e.g. new-blitz-60 (running out of time loses)
+- new-boN.%[1]s - Play a best-of-N series of rematches, e.g. new-bo5 (options combine: new-connect4-bo3)
+- new-first-RULE.%[1]s - Choose who moves first: x (default), o, random (coin toss), alternate or loser (of the last game)
+- new-from-POSITION.%[1]s - Start from a position, e.g. new-from-xo_x_o___-o (cells row by row, then the side to move), or from moves, e.g. new-from-b2-a1-c3 (must come last)
+- list.%[1]s - List all active sessions
 
 Game Commands (replace {session-id} with your session ID, {token} with your player token):
-- {session-id}.join.%s - Join a session and get your player token
-- {session-id}.board.%s - View current board
-- {session-id}-{token}-move-ROW-COL.%s - Make a move using your token
-- {session-id}.reset.%s - Reset the game
-- {session-id}.json.%s - Get board state as JSON
-- {session-id}.%s - View board (shortcut)
+- {session-id}.join.%[1]s - Join a session and get your player token
+- {session-id}.join-bot-LEVEL.%[1]s - Fill the free seat with a bot (easy, medium or hard)
+- {session-id}.board.%[1]s - View current board
+- {session-id}-{token}-move-ROW-COL.%[1]s - Make a move using your token
+- {session-id}-{token}-drop-COL.%[1]s - Drop a piece into a column (Connect Four)
+- {session-id}-{token}-play-BOARD-CELL.%[1]s - Play a cell (0-8) of a local board (0-8) (ultimate, Notakto)
+- {session-id}-{token}-move-ROW-COL-ROW-COL.%[1]s - Place a spooky mark in two cells (quantum)
+- {session-id}-{token}-collapse-ROW-COL.%[1]s - Choose the cell a cycle-closing mark collapses into (quantum)
+- {session-id}-{token}.board.%[1]s - View the board as your player sees it (also json, history and export; needed for fog of war)
+- {session-id}.vote-TEAM-ROW-COL.%[1]s - Vote for team x or o's next move in a crowd-play session (e.g. vote-x-1-1)
+- {session-id}.votes.%[1]s - Show the votes of the current round in a crowd-play session
+- {session-id}.reset.%[1]s - Reset the game
+- {session-id}.json.%[1]s - Get board state as JSON
+- {session-id}.history.%[1]s - List the moves played so far
+- {session-id}.replay-N.%[1]s - Show the board after move N (0 for the start), also for the previous game after a reset or rematch; {session-id}-{token}.replay-N shows a fog-of-war board as you saw it
+- {session-id}.export.%[1]s - Export the position and moves in compact notation (XO_X_O___:O, b2 a1 c3)
+- {session-id}-{token}.eval.%[1]s - Analyze the position as JSON: its value, every legal move's result and whether the last move was a blunder
+- {session-id}-{token}-undo.%[1]s - Ask the opponent to take back your last move
+- {session-id}-{token}-accept-undo.%[1]s - Accept the opponent's takeback request
+- {session-id}-{token}-hint.%[1]s - Suggest the best move and its expected result
+- {session-id}-{token}-resign.%[1]s - Resign the game
+- {session-id}-{token}-offer-draw.%[1]s - Offer a draw
+- {session-id}-{token}-accept-draw.%[1]s - Accept the opponent's draw offer
+- {session-id}-{token}-rematch.%[1]s - Start a new game with X and O swapped (keeps the series score)
+- {session-id}-{token}-first-RULE.%[1]s - Host only: choose who moves first (x, o, random, alternate or loser)
+- {session-id}.%[1]s - View board (shortcut)
 
 Example:
-1. dig @127.0.0.1 TXT new.%s  # Create session, get ID
-2. dig @127.0.0.1 TXT abc123.join.%s  # Join session, get token (assigned X or O)
-3. dig @127.0.0.1 TXT abc123-xyz78901-move-1-1.%s  # Make move with token`,
-		zoneExample, zoneExample, zoneExample, zoneExample, zoneExample, zoneExample, zoneExample, zoneExample, zoneExample, zoneExample, zoneExample)
+1. dig @127.0.0.1 TXT new.%[1]s  # Create session, get ID
+2. dig @127.0.0.1 TXT abc123.join.%[1]s  # Join session, get token (assigned X or O)
+3. dig @127.0.0.1 TXT abc123-xyz78901-move-1-1.%[1]s  # Make move with token`, zoneExample)
 	writeText(msg, qname, help, ttl)
 }
 
@@ -126,14 +386,32 @@ func WriteInvalidCommand(msg *dns.Msg, qname string, command string, validComman
 	writeText(msg, qname, response, ttl)
 }
 
-// WriteJoinSuccess writes a successful join response
-func WriteJoinSuccess(msg *dns.Msg, qname string, sessionID SessionID, token game.PlayerToken, player game.Player, ttl uint32, zone string) {
+// WriteJoinSuccess writes a successful join response, including who moves first
+// The move syntax shown depends on the session's game variant
+func WriteJoinSuccess(msg *dns.Msg, qname string, sessionID SessionID, token game.PlayerToken, player game.Player, session *game.Session, ttl uint32, zone string) {
 	zoneExample := strings.TrimSuffix(zone, ".")
-	response := fmt.Sprintf("Joined session: %s\nPlayer Token: %s\nYou are playing as: %s\n\nUse your token to make moves:\n%s-%s-move-ROW-COL.%s\n\nExample: %s-%s-move-1-1.%s",
-		sessionID, token, player, sessionID, token, zoneExample, sessionID, token, zoneExample)
+	moveFormat, moveExample := "move-ROW-COL", "move-1-1"
+	switch session.Game.GetState().Variant {
+	case game.VariantConnectFour:
+		moveFormat, moveExample = "drop-COL", "drop-3"
+	case game.VariantUltimate:
+		moveFormat, moveExample = "play-BOARD-CELL", "play-4-4"
+	case game.VariantQuantum:
+		moveFormat, moveExample = "move-ROW-COL-ROW-COL", "move-0-0-2-2"
+	case game.VariantNotakto:
+		moveFormat, moveExample = "play-BOARD-CELL", "play-0-4"
+	}
+	response := fmt.Sprintf("Joined session: %s\nPlayer Token: %s\nYou are playing as: %s\n%s\n\nUse your token to make moves:\n%s-%s-%s.%s\n\nExample: %s-%s-%s.%s",
+		sessionID, token, player, firstMoveLine(session), sessionID, token, moveFormat, zoneExample, sessionID, token, moveExample, zoneExample)
+	if _, ok := session.Game.(game.PlayerViewer); ok {
+		response += fmt.Sprintf("\n\nFog of war: view your own board with %s-%s.board.%s", sessionID, token, zoneExample)
+	}
 	writeText(msg, qname, response, ttl)
 }
 
+// maxTXTStringLength is the longest character-string a single TXT string can carry
+const maxTXTStringLength = 255
+
 // writeText writes text to the DNS response as a TXT record
 // TTL is set to 0 by default (configured via DNS_TTL env var) to prevent caching
 // Note: System DNS resolvers may enforce minimum TTL values
@@ -146,7 +424,24 @@ func writeText(msg *dns.Msg, qname string, text string, ttl uint32) {
 			Class:  dns.ClassINET,
 			Ttl:    ttl,
 		},
-		Txt: []string{text},
+		Txt: splitText(text),
 	}
 	msg.Answer = append(msg.Answer, txt)
 }
+
+// splitText splits text into 255-byte chunks so large boards fit in one TXT record
+// Clients join the strings of a TXT record back together
+func splitText(text string) []string {
+	if len(text) <= maxTXTStringLength {
+		return []string{text}
+	}
+	chunks := make([]string, 0, len(text)/maxTXTStringLength+1)
+	for len(text) > maxTXTStringLength {
+		chunks = append(chunks, text[:maxTXTStringLength])
+		text = text[maxTXTStringLength:]
+	}
+	if len(text) > 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
@@ -189,42 +189,10 @@ func (ds *Server) parseSubdomain(subdomain string, query *Query) {
 		return
 	}
 
-	// Check for move format: {session-id}-{token}-move-ROW-COL
-	// Session ID and token come first, then command
-	if strings.Contains(subdomain, "-move-") {
-		parts := strings.Split(subdomain, "-")
-		// Format: {session-id}-{token}-move-ROW-COL
-		// Minimum 5 parts: sessionID, token, "move", row, col
-		if len(parts) >= 5 {
-			// Find where "move" appears (should be at index 2)
-			moveIdx := -1
-			for i, part := range parts {
-				if part == "move" {
-					moveIdx = i
-					break
-				}
-			}
-			if moveIdx == 2 && len(parts) >= 5 {
-				sessionID := SessionID(parts[0])
-				if sessionID.IsValid() {
-					query.SessionID = sessionID
-					query.PlayerToken = game.PlayerToken(parts[1])
-					query.Command = CommandMove
-
-					// Parse move parameters (extract row and col)
-					var row, col int
-					if _, err := fmt.Sscanf(parts[3], "%d", &row); err == nil {
-						if _, err := fmt.Sscanf(parts[4], "%d", &col); err == nil {
-							query.MoveParams = &MoveParams{
-								Row: row,
-								Col: col,
-							}
-						}
-					}
-					return
-				}
-			}
-		}
+	// Check for token commands: {session-id}-{token}-{action}
+	// Session ID and token come first, then the action and its arguments
+	if !strings.Contains(subdomain, ".") && ds.parseTokenCommand(subdomain, query) {
+		return
 	}
 
 	// Parse session ID and command from subdomain
@@ -243,8 +211,12 @@ func (ds *Server) parseSubdomain(subdomain string, query *Query) {
 		return
 	}
 
-	// Format: {session-id}.{command}
+	// Format: {session-id}.{command}, or {session-id}-{token}.{command} for a player's own view
 	sessionID := SessionID(parts[0])
+	if id, token, found := strings.Cut(parts[0], "-"); found {
+		sessionID = SessionID(id)
+		query.PlayerToken = game.PlayerToken(token)
+	}
 	if !sessionID.IsValid() {
 		query.Command = CommandHelp
 		return
@@ -256,6 +228,28 @@ func (ds *Server) parseSubdomain(subdomain string, query *Query) {
 	// Parse command type
 	query.Command = ParseCommand(commandStr)
 
+	// If it's a join-bot command, parse the difficulty level
+	if query.Command == CommandJoinBot {
+		if difficulty, err := ParseJoinBotLevel(commandStr); err == nil {
+			query.BotDifficulty = difficulty
+		}
+	}
+
+	// If it's a replay command, parse the move number
+	if query.Command == CommandReplay {
+		query.ReplayMove = -1
+		if number, err := ParseReplayMove(commandStr); err == nil {
+			query.ReplayMove = number
+		}
+	}
+
+	// If it's a vote command, parse the vote
+	if query.Command == CommandVote {
+		if vote, err := ParseVoteParams(commandStr); err == nil {
+			query.Vote = vote
+		}
+	}
+
 	// If it's a move command, parse the move parameters
 	if query.Command == CommandMove {
 		moveParams, err := ParseMoveParams(commandStr)
@@ -268,6 +262,118 @@ func (ds *Server) parseSubdomain(subdomain string, query *Query) {
 	}
 }
 
+// parseTokenCommand parses commands authenticated by a player token
+// Formats: {session-id}-{token}-move-ROW-COL[-ROW-COL], {session-id}-{token}-drop-COL, {session-id}-{token}-play-BOARD-CELL,
+// {session-id}-{token}-collapse-ROW-COL,
+// {session-id}-{token}-first-RULE, {session-id}-{token}-ACTION for undo, accept-undo, hint, resign, offer-draw, accept-draw and rematch
+// Returns false if the subdomain is not a token command
+func (ds *Server) parseTokenCommand(subdomain string, query *Query) bool {
+	parts := strings.Split(subdomain, "-")
+	// Minimum 3 parts: sessionID, token, action
+	if len(parts) < 3 {
+		return false
+	}
+
+	sessionID := SessionID(parts[0])
+	if !sessionID.IsValid() {
+		return false
+	}
+
+	args := parts[3:]
+	switch command := ParseCommand(strings.Join(parts[2:], "-")); command {
+	case CommandMove:
+		// Format: {session-id}-{token}-move-ROW-COL, or move-ROW-COL-ROW-COL for a two-cell (quantum) move
+		if len(args) < 2 {
+			return false
+		}
+		query.Command = CommandMove
+
+		// Parse move parameters (extract row and col)
+		var row, col int
+		if _, err := fmt.Sscanf(args[0], "%d", &row); err == nil {
+			if _, err := fmt.Sscanf(args[1], "%d", &col); err == nil {
+				query.MoveParams = &MoveParams{
+					Row: row,
+					Col: col,
+				}
+			}
+		}
+		if query.MoveParams != nil && len(args) >= 4 {
+			var second game.Position
+			_, rowErr := fmt.Sscanf(args[2], "%d", &second.Row)
+			_, colErr := fmt.Sscanf(args[3], "%d", &second.Col)
+			if rowErr != nil || colErr != nil {
+				query.MoveParams = nil
+			} else {
+				query.MoveParams.Second = &second
+			}
+		}
+
+	case CommandCollapse:
+		// Format: {session-id}-{token}-collapse-ROW-COL
+		if len(args) < 2 {
+			return false
+		}
+		query.Command = CommandCollapse
+
+		var row, col int
+		if _, err := fmt.Sscanf(args[0], "%d", &row); err == nil {
+			if _, err := fmt.Sscanf(args[1], "%d", &col); err == nil {
+				query.MoveParams = &MoveParams{
+					Row: row,
+					Col: col,
+				}
+			}
+		}
+
+	case CommandDrop:
+		// Format: {session-id}-{token}-drop-COL
+		query.Command = CommandDrop
+
+		var col int
+		if _, err := fmt.Sscanf(args[0], "%d", &col); err == nil {
+			query.MoveParams = &MoveParams{
+				Col: col,
+			}
+		}
+
+	case CommandPlay:
+		// Format: {session-id}-{token}-play-BOARD-CELL
+		if len(args) < 2 {
+			return false
+		}
+		query.Command = CommandPlay
+
+		var board, cell int
+		if _, err := fmt.Sscanf(args[0], "%d", &board); err == nil {
+			if _, err := fmt.Sscanf(args[1], "%d", &cell); err == nil {
+				query.MoveParams = &MoveParams{
+					Board: board,
+					Cell:  cell,
+				}
+			}
+		}
+
+	case CommandUndo, CommandAcceptUndo, CommandHint, CommandResign, CommandOfferDraw, CommandAcceptDraw, CommandRematch:
+		// Format: {session-id}-{token}-ACTION
+		query.Command = command
+
+	case CommandFirst:
+		// Format: {session-id}-{token}-first-RULE
+		query.Command = CommandFirst
+		if rule, err := game.ParseFirstMove(strings.Join(args, "-")); err == nil && len(args) > 0 {
+			query.FirstMove = rule
+		}
+
+	default:
+		return false
+	}
+
+	query.SessionID = sessionID
+	query.PlayerToken = game.PlayerToken(parts[1])
+	return true
+}
+
 // handleQuery processes a parsed query
 func (ds *Server) handleQuery(m *dns.Msg, qname string, query *Query, _ dns.ResponseWriter) {
 	if query.IsSessionManagement() {
@@ -288,7 +394,7 @@ func (ds *Server) handleQuery(m *dns.Msg, qname string, query *Query, _ dns.Resp
 func (ds *Server) handleSessionManagement(m *dns.Msg, qname string, query *Query) {
 	switch query.Command {
 	case CommandNew, CommandCreate:
-		ds.handleCreateSession(m, qname)
+		ds.handleCreateSession(m, qname, query)
 
 	case CommandList, CommandSessions:
 		ds.handleListSessions(m, qname)
@@ -301,14 +407,24 @@ func (ds *Server) handleSessionManagement(m *dns.Msg, qname string, query *Query
 	}
 }
 
-// handleCreateSession creates a new game session
-func (ds *Server) handleCreateSession(m *dns.Msg, qname string) {
-	sessionID, err := ds.sessionManager.CreateSession()
+// handleCreateSession creates a new game session, applying any options from the query (e.g., new-15x15-5)
+func (ds *Server) handleCreateSession(m *dns.Msg, qname string, query *Query) {
+	opts, err := ParseCreateOptions(query.RawQuery)
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+
+	sessionID, err := ds.sessionManager.CreateSession(opts...)
 	if err != nil {
 		dnsErr := NewSessionCreateError(err)
 		WriteError(m, qname, dnsErr, ds.ttl)
 		return
 	}
+	if session, err := ds.sessionManager.GetSession(sessionID); err == nil && session.IsCrowd() {
+		WriteCrowdSessionCreated(m, qname, SessionID(sessionID), ds.ttl, string(ds.zone))
+		return
+	}
 	WriteSessionCreated(m, qname, SessionID(sessionID), ds.ttl, string(ds.zone))
 }
 
@@ -334,42 +450,195 @@ func (ds *Server) handleGameCommand(m *dns.Msg, qname string, query *Query) {
 	case CommandJoin:
 		ds.handleJoinCommand(m, qname, query.SessionID, session)
 
+	case CommandJoinBot:
+		ds.handleJoinBotCommand(m, qname, query, session)
+
 	case CommandBoard, CommandStatus:
-		ds.handleBoardCommand(m, qname, query.SessionID, session)
+		ds.handleBoardCommand(m, qname, query, session)
 
 	case CommandMove:
 		ds.handleMoveCommand(m, qname, query, session)
 
+	case CommandDrop:
+		ds.handleDropCommand(m, qname, query, session)
+
+	case CommandPlay:
+		ds.handlePlayCommand(m, qname, query, session)
+
+	case CommandCollapse:
+		ds.handleCollapseCommand(m, qname, query, session)
+
 	case CommandReset:
 		ds.handleResetCommand(m, qname, query.SessionID, session)
 
 	case CommandJSON:
-		ds.handleJSONCommand(m, qname, session)
+		ds.handleJSONCommand(m, qname, query, session)
+
+	case CommandHistory:
+		ds.handleHistoryCommand(m, qname, query, session)
+
+	case CommandUndo:
+		ds.handleUndoCommand(m, qname, query, session)
+
+	case CommandAcceptUndo:
+		ds.handleAcceptUndoCommand(m, qname, query, session)
+
+	case CommandHint:
+		ds.handleHintCommand(m, qname, query, session)
+
+	case CommandEval:
+		ds.handleEvalCommand(m, qname, query, session)
+
+	case CommandResign:
+		ds.handleResignCommand(m, qname, query, session)
+
+	case CommandOfferDraw:
+		ds.handleOfferDrawCommand(m, qname, query, session)
+
+	case CommandAcceptDraw:
+		ds.handleAcceptDrawCommand(m, qname, query, session)
+
+	case CommandRematch:
+		ds.handleRematchCommand(m, qname, query, session)
+
+	case CommandExport:
+		ds.handleExportCommand(m, qname, query, session)
+
+	case CommandReplay:
+		ds.handleReplayCommand(m, qname, query, session)
+
+	case CommandVote:
+		ds.handleVoteCommand(m, qname, query, session)
+
+	case CommandVotes:
+		ds.handleVotesCommand(m, qname, query.SessionID, session)
+
+	case CommandFirst:
+		ds.handleFirstCommand(m, qname, query, session)
 
 	default:
-		validCommands := []string{"join", "board", "reset", "json"}
+		validCommands := []string{"join", "join-bot-LEVEL", "board", "reset", "json", "history", "eval", "export", "replay-N", "vote-TEAM-ROW-COL", "votes"}
 		WriteInvalidCommand(m, qname, query.RawQuery, validCommands, ds.ttl)
 	}
+
+	// Save changes straight away instead of with the next periodic save, so a crash does not lose them
+	if query.Command.ChangesSession() {
+		if err := ds.sessionManager.SaveSession(session); err != nil {
+			log.Printf("Failed to save session %s: %v", session.ID, err)
+		}
+	}
 }
 
 // handleBoardCommand handles board view commands
-func (ds *Server) handleBoardCommand(m *dns.Msg, qname string, sessionID SessionID, session *game.Session) {
-	WriteBoard(m, qname, sessionID, session.Game, ds.ttl)
+func (ds *Server) handleBoardCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	viewer, ok := ds.resolveViewer(m, qname, query, session)
+	if !ok {
+		return
+	}
+	session.Touch()
+	WriteBoard(m, qname, query.SessionID, session, viewer, ds.ttl)
 }
 
 // handleResetCommand handles reset commands
 func (ds *Server) handleResetCommand(m *dns.Msg, qname string, sessionID SessionID, session *game.Session) {
-	session.Game.Reset()
-	// After reset, if both players are still in, start the game
-	if session.GetPlayerCount() == 2 {
+	if err := session.Reset(); err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+	// After reset, if all players are still in, start the game
+	if session.GetPlayerCount() == session.SeatCount() {
 		session.Game.StartGame()
+		// A bot playing X opens the new game
+		ds.playBotTurn(session)
 	}
-	WriteReset(m, qname, sessionID, session.Game, ds.ttl)
+	WriteReset(m, qname, sessionID, session, "", ds.ttl)
 }
 
 // handleJSONCommand handles JSON state commands
-func (ds *Server) handleJSONCommand(m *dns.Msg, qname string, session *game.Session) {
-	WriteJSONWithSession(m, qname, session.Game, session, ds.ttl)
+func (ds *Server) handleJSONCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	viewer, ok := ds.resolveViewer(m, qname, query, session)
+	if !ok {
+		return
+	}
+	// The web interface views the board through the JSON state
+	session.Touch()
+	WriteJSONWithSession(m, qname, session.StateFor(viewer), session, ds.ttl)
+}
+
+// handleEvalCommand handles position analysis commands
+// Analysis can keep the solver busy for up to a second, so only the session's players may ask for it
+func (ds *Server) handleEvalCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	if _, ok := ds.resolvePlayer(m, qname, query, session); !ok {
+		return
+	}
+	analysis, err := session.Analyze()
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+	WriteAnalysisJSON(m, qname, analysis, ds.ttl)
+}
+
+// handleHistoryCommand handles move history commands
+func (ds *Server) handleHistoryCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	viewer, ok := ds.resolveViewer(m, qname, query, session)
+	if !ok {
+		return
+	}
+	WriteHistory(m, qname, query.SessionID, session.StateFor(viewer), ds.ttl)
+}
+
+// handleExportCommand handles position export commands
+func (ds *Server) handleExportCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	viewer, ok := ds.resolveViewer(m, qname, query, session)
+	if !ok {
+		return
+	}
+	WriteExport(m, qname, query.SessionID, session.StateFor(viewer), ds.ttl, string(ds.zone))
+}
+
+// handleReplayCommand handles replay commands, showing the board after a given move
+func (ds *Server) handleReplayCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	if query.ReplayMove < 0 {
+		WriteError(m, qname, NewInvalidReplayFormatError(query.RawQuery), ds.ttl)
+		return
+	}
+
+	viewer, ok := ds.resolveViewer(m, qname, query, session)
+	if !ok {
+		return
+	}
+	replay, err := session.Replay(query.ReplayMove, viewer)
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+	WriteReplay(m, qname, query.SessionID, replay, ds.ttl)
+}
+
+// handleVoteCommand counts a crowd-play vote for a team's next move
+func (ds *Server) handleVoteCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	if query.Vote == nil {
+		WriteError(m, qname, NewInvalidVoteFormatError(query.RawQuery), ds.ttl)
+		return
+	}
+
+	votes, err := session.Vote(query.Vote.Team, query.Vote.Row, query.Vote.Col)
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+	WriteVoteCounted(m, qname, query.SessionID, query.Vote, votes, ds.ttl)
+}
+
+// handleVotesCommand shows the tally of a crowd-play session's current voting round
+func (ds *Server) handleVotesCommand(m *dns.Msg, qname string, sessionID SessionID, session *game.Session) {
+	votes, err := session.Votes()
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+	WriteVotes(m, qname, sessionID, votes, session, ds.ttl)
 }
 
 // writeNSRecord writes an NS record for the zone
@@ -434,42 +703,361 @@ func (ds *Server) handleJoinCommand(m *dns.Msg, qname string, sessionID SessionI
 		WriteError(m, qname, err, ds.ttl)
 		return
 	}
-	WriteJoinSuccess(m, qname, sessionID, token, player, ds.ttl, string(ds.zone))
+	// A bot that took the X seat first opens the game once the human joins
+	ds.playBotTurn(session)
+	WriteJoinSuccess(m, qname, sessionID, token, player, session, ds.ttl, string(ds.zone))
+}
+
+// handleJoinBotCommand fills the session's free seat with a bot
+func (ds *Server) handleJoinBotCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	if query.BotDifficulty == "" {
+		WriteError(m, qname, NewInvalidBotLevelError(query.RawQuery), ds.ttl)
+		return
+	}
+
+	if _, err := session.JoinBot(query.BotDifficulty); err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
+	}
+
+	// If the bot took X and the game has started, it opens
+	WriteBotJoined(m, qname, query.SessionID, session.GetBot(), ds.playBotTurn(session), session, "", ds.ttl)
+}
+
+// playBotTurn lets the session's bot answer and returns a description of its move,
+// or an empty string if it was not the bot's turn
+func (ds *Server) playBotTurn(session *game.Session) string {
+	move, err := session.PlayBotTurn()
+	if err != nil {
+		log.Printf("Bot move failed in session %s: %v", session.ID, err)
+		return ""
+	}
+	if move == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s played %d-%d", session.GetBot(), move.Row, move.Col)
 }
 
 // handleMoveCommand processes a move command from the DNS query
 func (ds *Server) handleMoveCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
 	// Validate move parameters
-	if query.MoveParams == nil || !query.MoveParams.IsValid() {
+	if query.MoveParams == nil || !query.MoveParams.IsValid() || !query.MoveParams.IsSecondValid() {
 		dnsErr := NewInvalidMoveFormatError(query.RawQuery)
 		WriteError(m, qname, dnsErr, ds.ttl)
 		return
 	}
 
-	// Check if both players have joined
-	if session.GetPlayerCount() < 2 {
-		WriteError(m, qname, fmt.Errorf("waiting for players to join (need 2 players)"), ds.ttl)
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
 		return
 	}
 
+	// Validate the position against the session's board dimensions
+	state := session.Game.GetState()
+	if !query.MoveParams.IsWithin(state.Rows, state.Cols) {
+		err := game.NewInvalidPositionError(query.MoveParams.Row, query.MoveParams.Col, state.Rows, state.Cols)
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+
+	// Execute the move, in both cells for a two-cell move
+	var err error
+	if second := query.MoveParams.Second; second != nil {
+		mover, ok := session.Game.(game.QuantumMover)
+		if !ok {
+			WriteError(m, qname, NewUnsupportedCommandError("move-ROW-COL-ROW-COL", state.Variant), ds.ttl)
+			return
+		}
+		first := game.Position{Row: query.MoveParams.Row, Col: query.MoveParams.Col}
+		err = mover.MakeQuantumMove(first, *second, player)
+	} else {
+		err = session.Game.MakeMove(query.MoveParams.Row, query.MoveParams.Col, player)
+	}
+	if err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+	} else {
+		WriteMoveAccepted(m, qname, query.SessionID, ds.playBotTurn(session), session, player, ds.ttl)
+	}
+}
+
+// handleDropCommand processes a column drop command (Connect Four) from the DNS query
+func (ds *Server) handleDropCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	// Validate drop parameters
+	if query.MoveParams == nil || query.MoveParams.Col < 0 {
+		dnsErr := NewInvalidDropFormatError(query.RawQuery)
+		WriteError(m, qname, dnsErr, ds.ttl)
+		return
+	}
+
+	dropper, ok := session.Game.(game.ColumnDropper)
+	if !ok {
+		WriteError(m, qname, NewUnsupportedCommandError(CommandDrop, session.Game.GetState().Variant), ds.ttl)
+		return
+	}
+
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	// Validate the column against the session's board dimensions
+	state := session.Game.GetState()
+	if query.MoveParams.Col >= state.Cols {
+		err := game.NewInvalidColumnError(query.MoveParams.Col, state.Cols)
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+
+	// Execute the drop
+	err := dropper.DropPiece(query.MoveParams.Col, player)
+	if err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+	} else {
+		WriteMoveAccepted(m, qname, query.SessionID, ds.playBotTurn(session), session, player, ds.ttl)
+	}
+}
+
+// handlePlayCommand processes a board-and-cell move (ultimate tic-tac-toe) from the DNS query
+func (ds *Server) handlePlayCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	// Validate play parameters
+	if query.MoveParams == nil || query.MoveParams.Board < 0 || query.MoveParams.Cell < 0 {
+		dnsErr := NewInvalidPlayFormatError(query.RawQuery)
+		WriteError(m, qname, dnsErr, ds.ttl)
+		return
+	}
+
+	mover, ok := session.Game.(game.SubBoardMover)
+	if !ok {
+		WriteError(m, qname, NewUnsupportedCommandError(CommandPlay, session.Game.GetState().Variant), ds.ttl)
+		return
+	}
+
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	// Execute the move (the engine validates board and cell ranges)
+	err := mover.MakeSubBoardMove(query.MoveParams.Board, query.MoveParams.Cell, player)
+	if err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+	} else {
+		WriteMoveAccepted(m, qname, query.SessionID, ds.playBotTurn(session), session, player, ds.ttl)
+	}
+}
+
+// handleCollapseCommand processes the choice of how a cycle of spooky marks collapses (quantum tic-tac-toe)
+func (ds *Server) handleCollapseCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	// Validate collapse parameters
+	if query.MoveParams == nil || !query.MoveParams.IsValid() {
+		dnsErr := NewInvalidCollapseFormatError(query.RawQuery)
+		WriteError(m, qname, dnsErr, ds.ttl)
+		return
+	}
+
+	mover, ok := session.Game.(game.QuantumMover)
+	if !ok {
+		WriteError(m, qname, NewUnsupportedCommandError(CommandCollapse, session.Game.GetState().Variant), ds.ttl)
+		return
+	}
+
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	// Execute the collapse (the engine checks the cell is one of the cycle-closing mark's cells)
+	err := mover.Collapse(game.Position{Row: query.MoveParams.Row, Col: query.MoveParams.Col}, player)
+	if err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+	} else {
+		WriteMoveAccepted(m, qname, query.SessionID, ds.playBotTurn(session), session, player, ds.ttl)
+	}
+}
+
+// resolvePlayer looks up the player for the query's token once every seat is taken
+// Writes an error response and returns false if the player cannot act
+func (ds *Server) resolvePlayer(m *dns.Msg, qname string, query *Query, session *game.Session) (game.Player, bool) {
+	// Check if all players have joined
+	if seats := session.SeatCount(); session.GetPlayerCount() < seats {
+		WriteError(m, qname, fmt.Errorf("waiting for players to join (need %d players)", seats), ds.ttl)
+		return "", false
+	}
+
 	// Get player from token
 	playerToken := query.PlayerToken
 	if playerToken == "" {
 		WriteError(m, qname, fmt.Errorf("player token is required"), ds.ttl)
-		return
+		return "", false
 	}
 
 	player, err := session.GetPlayer(playerToken)
 	if err != nil {
 		WriteError(m, qname, err, ds.ttl)
+		return "", false
+	}
+
+	return player, true
+}
+
+// resolveViewer looks up whose view of the game a read-only command shows: the player of the query's token,
+// or "" for spectators when there is no token
+// Writes an error response and returns false if the token is not part of the session
+func (ds *Server) resolveViewer(m *dns.Msg, qname string, query *Query, session *game.Session) (game.Player, bool) {
+	if query.PlayerToken == "" {
+		return "", true
+	}
+	player, err := session.GetPlayer(query.PlayerToken)
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return "", false
+	}
+	return player, true
+}
+
+// handleUndoCommand records a takeback request for the player's last move
+// A bot opponent accepts straight away
+func (ds *Server) handleUndoCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	if err := session.Game.RequestUndo(player); err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+
+	if bot := session.GetBot(); bot != nil {
+		undone, err := session.Game.AcceptUndo(bot.Player)
+		if err != nil {
+			WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+			return
+		}
+		WriteUndoAccepted(m, qname, query.SessionID, undone, session, player, ds.ttl)
 		return
 	}
 
-	// Execute the move
-	err = session.Game.MakeMove(query.MoveParams.Row, query.MoveParams.Col, player)
+	WriteUndoRequested(m, qname, query.SessionID, player, session, player, ds.ttl)
+}
+
+// handleAcceptUndoCommand accepts the opponent's pending takeback request
+func (ds *Server) handleAcceptUndoCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	undone, err := session.Game.AcceptUndo(player)
 	if err != nil {
-		WriteMoveError(m, qname, query.SessionID, err, session.Game, ds.ttl)
-	} else {
-		WriteMoveAccepted(m, qname, query.SessionID, session.Game, ds.ttl)
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+	WriteUndoAccepted(m, qname, query.SessionID, undone, session, player, ds.ttl)
+}
+
+// handleHintCommand suggests the best move for the player in the current position
+func (ds *Server) handleHintCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	hint, err := session.Hint(player)
+	if err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+	WriteHint(m, qname, query.SessionID, hint, session, player, ds.ttl)
+}
+
+// handleResignCommand ends the game with a win for the player's opponent
+func (ds *Server) handleResignCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	if err := session.Game.Resign(player); err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+	WriteBoardWithMessage(m, qname, query.SessionID, fmt.Sprintf("%s resigned", player), session, player, ds.ttl)
+}
+
+// handleOfferDrawCommand records a draw offer; a bot opponent answers straight away
+func (ds *Server) handleOfferDrawCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	if err := session.Game.OfferDraw(player); err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+
+	if bot := session.GetBot(); bot != nil {
+		accepted, err := session.AnswerDrawOffer()
+		if err != nil {
+			log.Printf("Bot draw answer failed in session %s: %v", session.ID, err)
+		}
+		WriteDrawOffered(m, qname, query.SessionID, player, bot, accepted, session, player, ds.ttl)
+		return
+	}
+
+	WriteDrawOffered(m, qname, query.SessionID, player, nil, false, session, player, ds.ttl)
+}
+
+// handleAcceptDrawCommand accepts the opponent's pending draw offer
+func (ds *Server) handleAcceptDrawCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	if err := session.Game.AcceptDraw(player); err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+	WriteBoardWithMessage(m, qname, query.SessionID, "Draw agreed", session, player, ds.ttl)
+}
+
+// handleFirstCommand lets the host choose who moves first; a bot that is now to move opens the game
+func (ds *Server) handleFirstCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	if query.FirstMove == "" {
+		WriteError(m, qname, NewInvalidFirstMoveFormatError(query.RawQuery), ds.ttl)
+		return
+	}
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	if err := session.SetFirstMove(query.PlayerToken, query.FirstMove); err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+	WriteFirstMoveSet(m, qname, query.SessionID, ds.playBotTurn(session), session, player, ds.ttl)
+}
+
+// handleRematchCommand starts a new game in the session with X and O swapped
+func (ds *Server) handleRematchCommand(m *dns.Msg, qname string, query *Query, session *game.Session) {
+	player, ok := ds.resolvePlayer(m, qname, query, session)
+	if !ok {
+		return
+	}
+
+	if err := session.Rematch(); err != nil {
+		WriteMoveError(m, qname, query.SessionID, err, session, player, ds.ttl)
+		return
+	}
+
+	// The player's side changed with the swap
+	player, err := session.GetPlayer(query.PlayerToken)
+	if err != nil {
+		WriteError(m, qname, err, ds.ttl)
+		return
 	}
+	WriteRematch(m, qname, query.SessionID, player, ds.playBotTurn(session), session, player, ds.ttl)
 }
@@ -2,7 +2,9 @@ package dns
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"dns-tic-tac-toe/pkg/game"
 )
@@ -11,18 +13,36 @@ import (
 type Command string
 
 const (
-	CommandNew      Command = "new"
-	CommandCreate   Command = "create"
-	CommandList     Command = "list"
-	CommandSessions Command = "sessions"
-	CommandHelp     Command = "help"
-	CommandJoin     Command = "join"
-	CommandBoard    Command = "board"
-	CommandStatus   Command = "status"
-	CommandMove     Command = "move"
-	CommandReset    Command = "reset"
-	CommandJSON     Command = "json"
-	CommandUnknown  Command = "unknown"
+	CommandNew        Command = "new"
+	CommandCreate     Command = "create"
+	CommandList       Command = "list"
+	CommandSessions   Command = "sessions"
+	CommandHelp       Command = "help"
+	CommandJoin       Command = "join"
+	CommandJoinBot    Command = "join-bot"
+	CommandBoard      Command = "board"
+	CommandStatus     Command = "status"
+	CommandMove       Command = "move"
+	CommandDrop       Command = "drop"
+	CommandPlay       Command = "play"
+	CommandReset      Command = "reset"
+	CommandJSON       Command = "json"
+	CommandHistory    Command = "history"
+	CommandUndo       Command = "undo"
+	CommandAcceptUndo Command = "accept-undo"
+	CommandHint       Command = "hint"
+	CommandEval       Command = "eval"
+	CommandResign     Command = "resign"
+	CommandOfferDraw  Command = "offer-draw"
+	CommandAcceptDraw Command = "accept-draw"
+	CommandRematch    Command = "rematch"
+	CommandExport     Command = "export"
+	CommandReplay     Command = "replay"
+	CommandFirst      Command = "first"
+	CommandCollapse   Command = "collapse"
+	CommandVote       Command = "vote"
+	CommandVotes      Command = "votes"
+	CommandUnknown    Command = "unknown"
 )
 
 // IsValid checks if the command is valid
@@ -37,7 +57,17 @@ func (c Command) IsSessionManagement() bool {
 
 // IsGameCommand returns true if the command is a game command
 func (c Command) IsGameCommand() bool {
-	return c == CommandJoin || c == CommandBoard || c == CommandStatus || c == CommandMove || c == CommandReset || c == CommandJSON
+	return c == CommandJoin || c == CommandJoinBot || c == CommandBoard || c == CommandStatus || c == CommandMove || c == CommandDrop || c == CommandPlay || c == CommandReset || c == CommandJSON || c == CommandHistory ||
+		c == CommandUndo || c == CommandAcceptUndo || c == CommandHint || c == CommandEval ||
+		c == CommandResign || c == CommandOfferDraw || c == CommandAcceptDraw || c == CommandRematch || c == CommandExport || c == CommandReplay || c == CommandFirst || c == CommandCollapse ||
+		c == CommandVote || c == CommandVotes
+}
+
+// ChangesSession returns true if the command may change the session, so it should be saved afterwards
+func (c Command) ChangesSession() bool {
+	return c == CommandJoin || c == CommandJoinBot || c == CommandMove || c == CommandDrop || c == CommandPlay || c == CommandCollapse || c == CommandReset ||
+		c == CommandUndo || c == CommandAcceptUndo || c == CommandResign || c == CommandOfferDraw || c == CommandAcceptDraw || c == CommandRematch ||
+		c == CommandFirst || c == CommandVote
 }
 
 // ParseCommand parses a string into a Command type
@@ -59,10 +89,56 @@ func ParseCommand(cmdStr string) Command {
 		return CommandReset
 	case "json":
 		return CommandJSON
+	case "history":
+		return CommandHistory
+	case "undo":
+		return CommandUndo
+	case "accept-undo":
+		return CommandAcceptUndo
+	case "hint":
+		return CommandHint
+	case "eval":
+		return CommandEval
+	case "resign":
+		return CommandResign
+	case "offer-draw":
+		return CommandOfferDraw
+	case "accept-draw":
+		return CommandAcceptDraw
+	case "rematch":
+		return CommandRematch
+	case "export":
+		return CommandExport
+	case "votes":
+		return CommandVotes
 	default:
+		if strings.HasPrefix(cmdStr, "new-") {
+			return CommandNew
+		}
+		if cmdStr == "join-bot" || strings.HasPrefix(cmdStr, "join-bot-") {
+			return CommandJoinBot
+		}
 		if strings.HasPrefix(cmdStr, "move-") {
 			return CommandMove
 		}
+		if strings.HasPrefix(cmdStr, "drop-") {
+			return CommandDrop
+		}
+		if strings.HasPrefix(cmdStr, "play-") {
+			return CommandPlay
+		}
+		if strings.HasPrefix(cmdStr, "replay-") {
+			return CommandReplay
+		}
+		if strings.HasPrefix(cmdStr, "first-") {
+			return CommandFirst
+		}
+		if strings.HasPrefix(cmdStr, "collapse-") {
+			return CommandCollapse
+		}
+		if strings.HasPrefix(cmdStr, "vote-") {
+			return CommandVote
+		}
 		return CommandUnknown
 	}
 }
@@ -86,15 +162,31 @@ func (id SessionID) String() string {
 }
 
 // MoveParams represents the parameters for a move command
+// Row and Col address a cell (Col alone for drops), Board and Cell address a cell of a local board
+// Second is the other cell of a quantum tic-tac-toe move, nil for single-cell moves
 type MoveParams struct {
 	Row         int
 	Col         int
+	Board       int
+	Cell        int
+	Second      *game.Position
 	PlayerToken string
 }
 
 // IsValid validates the move parameters (position only, token validation happens in server)
+// Bounds depend on the session's board, see IsWithin
 func (m *MoveParams) IsValid() bool {
-	return m.Row >= 0 && m.Row < 3 && m.Col >= 0 && m.Col < 3
+	return m.Row >= 0 && m.Col >= 0
+}
+
+// IsWithin checks that the move falls on a rows x cols board
+func (m *MoveParams) IsWithin(rows, cols int) bool {
+	return m.IsValid() && m.Row < rows && m.Col < cols
+}
+
+// IsSecondValid validates the second cell of a two-cell move, if any
+func (m *MoveParams) IsSecondValid() bool {
+	return m.Second == nil || (m.Second.Row >= 0 && m.Second.Col >= 0)
 }
 
 // ParseMoveParams parses a move command string into MoveParams
@@ -132,19 +224,241 @@ func ParseMoveParams(moveStr string) (*MoveParams, error) {
 	}
 
 	if !params.IsValid() {
-		return nil, fmt.Errorf("invalid move parameters: row=%d, col=%d (must be 0-2)", row, col)
+		return nil, fmt.Errorf("invalid move parameters: row=%d, col=%d (must not be negative)", row, col)
 	}
 
 	return params, nil
 }
 
+// ParseCreateOptions parses the options of a session creation command into session options
+// Format: new[-VARIANT][-ROWSxCOLS-K][-vs-ai[-LEVEL]][-first-RULE][-from-POSITION] (e.g., new, new-4x4-3, new-15x15-5, new-connect4, new-ultimate, new-quantum, new-notakto-3, new-misere, new-fog, new-9x9-4-players-3, new-9x9-4-players-4-symbols-ab, new-crowd-20, new-vs-ai-hard, new-from-xo_x_o___-o)
+// from-POSITION takes the rest of the command, so it must come last
+func ParseCreateOptions(cmdStr string) ([]game.SessionOption, error) {
+	cmdStr = strings.ToLower(strings.TrimSpace(cmdStr))
+	if cmdStr == "new" || cmdStr == "create" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(cmdStr, "new-") {
+		return nil, NewInvalidCreateOptionsError(cmdStr, "must start with 'new-'")
+	}
+
+	var opts []game.SessionOption
+	tokens := strings.Split(strings.TrimPrefix(cmdStr, "new-"), "-")
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token == "connect4" || token == "c4":
+			opts = append(opts, game.WithVariant(game.VariantConnectFour))
+
+		case token == "ultimate" || token == "uttt":
+			opts = append(opts, game.WithVariant(game.VariantUltimate))
+
+		case token == "quantum" || token == "qttt":
+			opts = append(opts, game.WithVariant(game.VariantQuantum))
+
+		case token == "notakto":
+			// Notakto, optionally followed by the number of boards
+			opts = append(opts, game.WithVariant(game.VariantNotakto))
+			if i+1 < len(tokens) {
+				if boards, err := strconv.Atoi(tokens[i+1]); err == nil {
+					opts = append(opts, game.WithBoards(boards))
+					i++
+				}
+			}
+
+		case token == "fog":
+			opts = append(opts, game.WithVariant(game.VariantFog))
+
+		case token == "misere":
+			opts = append(opts, game.WithMisere())
+
+		case token == "crowd":
+			// Crowd play, optionally followed by the voting window in seconds
+			var window time.Duration
+			if i+1 < len(tokens) {
+				if seconds, err := strconv.Atoi(tokens[i+1]); err == nil {
+					if seconds < 1 {
+						return nil, NewInvalidCreateOptionsError(cmdStr, fmt.Sprintf("invalid voting window %q", tokens[i+1]))
+					}
+					window = time.Duration(seconds) * time.Second
+					i++
+				}
+			}
+			opts = append(opts, game.WithCrowd(window))
+
+		case token == "players":
+			// Seat count: players-N
+			if i+1 >= len(tokens) {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected players-N")
+			}
+			players, err := strconv.Atoi(tokens[i+1])
+			if err != nil {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected players-N")
+			}
+			opts = append(opts, game.WithPlayers(players))
+			i++
+
+		case token == "symbols":
+			// Symbols of the seats after X and O: symbols-CHARS, one character per seat, shown in upper case
+			if i+1 >= len(tokens) {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected symbols-CHARS")
+			}
+			i++
+			var symbols []game.Player
+			for _, r := range strings.ToUpper(tokens[i]) {
+				symbols = append(symbols, game.Player(r))
+			}
+			opts = append(opts, game.WithSymbols(symbols...))
+
+		case token == "vs":
+			// Bot opponent: vs-ai, optionally followed by a difficulty level
+			if i+1 >= len(tokens) || tokens[i+1] != "ai" {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected vs-ai[-LEVEL]")
+			}
+			i++
+			difficulty := game.DifficultyMedium
+			if i+1 < len(tokens) {
+				if level, err := game.ParseDifficulty(tokens[i+1]); err == nil {
+					difficulty = level
+					i++
+				}
+			}
+			opts = append(opts, game.WithBot(difficulty))
+
+		case token == "blitz":
+			// Time control: blitz-SECONDS per player
+			var seconds int
+			if i+1 >= len(tokens) {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected blitz-SECONDS")
+			}
+			i++
+			if _, err := fmt.Sscanf(tokens[i], "%d", &seconds); err != nil || seconds < 1 {
+				return nil, NewInvalidCreateOptionsError(cmdStr, fmt.Sprintf("invalid clock time %q", tokens[i]))
+			}
+			opts = append(opts, game.WithTimeControl(time.Duration(seconds)*time.Second))
+
+		case token == "first":
+			// First-move rule: first-x, first-o, first-random, first-alternate or first-loser
+			if i+1 >= len(tokens) {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected first-RULE")
+			}
+			i++
+			rule, err := game.ParseFirstMove(tokens[i])
+			if err != nil {
+				return nil, NewInvalidCreateOptionsError(cmdStr, err.Error())
+			}
+			opts = append(opts, game.WithFirstMove(rule))
+
+		case token == "from":
+			// Start position: the rest of the command, a position (e.g. xo_x_o___-o) or a move list (e.g. b2-a1-c3)
+			if i+1 >= len(tokens) {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected from-POSITION or from-MOVES")
+			}
+			opts = append(opts, game.WithStartPosition(strings.Join(tokens[i+1:], "-")))
+			i = len(tokens)
+
+		case strings.HasPrefix(token, "bo") && len(token) > 2:
+			// Series length: boN, e.g. bo5
+			var bestOf int
+			if _, err := fmt.Sscanf(token, "bo%d", &bestOf); err != nil || bestOf < 1 {
+				return nil, NewInvalidCreateOptionsError(cmdStr, fmt.Sprintf("invalid series length %q", token))
+			}
+			opts = append(opts, game.WithBestOf(bestOf))
+
+		case strings.Contains(token, "x"):
+			// Board size: ROWSxCOLS, followed by the win length K
+			var rows, cols, winLength int
+			if _, err := fmt.Sscanf(token, "%dx%d", &rows, &cols); err != nil {
+				return nil, NewInvalidCreateOptionsError(cmdStr, fmt.Sprintf("invalid board size %q", token))
+			}
+			if i+1 >= len(tokens) {
+				return nil, NewInvalidCreateOptionsError(cmdStr, "expected ROWSxCOLS-K")
+			}
+			i++
+			if _, err := fmt.Sscanf(tokens[i], "%d", &winLength); err != nil {
+				return nil, NewInvalidCreateOptionsError(cmdStr, fmt.Sprintf("invalid win length %q", tokens[i]))
+			}
+			opts = append(opts, game.WithBoard(rows, cols, winLength))
+
+		default:
+			return nil, NewInvalidCreateOptionsError(cmdStr, fmt.Sprintf("unknown option %q", token))
+		}
+	}
+
+	return opts, nil
+}
+
+// ParseJoinBotLevel parses the difficulty of a join-bot command
+// Format: join-bot[-LEVEL] (e.g., join-bot, join-bot-hard); the level defaults to medium
+func ParseJoinBotLevel(cmdStr string) (game.Difficulty, error) {
+	cmdStr = strings.ToLower(strings.TrimSpace(cmdStr))
+	level := strings.TrimPrefix(strings.TrimPrefix(cmdStr, "join-bot"), "-")
+	return game.ParseDifficulty(level)
+}
+
+// ParseReplayMove parses the move number of a replay command
+// Format: replay-N (e.g., replay-0 for the starting position, replay-3 for the position after move 3)
+func ParseReplayMove(cmdStr string) (int, error) {
+	var number int
+	var rest string
+	cmdStr = strings.ToLower(strings.TrimSpace(cmdStr))
+	if n, _ := fmt.Sscanf(cmdStr, "replay-%d%s", &number, &rest); n != 1 || number < 0 {
+		return 0, fmt.Errorf("invalid replay format: expected replay-N, got %s", cmdStr)
+	}
+	return number, nil
+}
+
+// VoteParams represents a crowd-play vote for a team's next move
+type VoteParams struct {
+	Team game.Player
+	Row  int
+	Col  int
+}
+
+// ParseVoteParams parses a vote command
+// Format: vote-TEAM-ROW-COL with TEAM x or o (e.g., vote-x-1-1)
+func ParseVoteParams(cmdStr string) (*VoteParams, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(cmdStr)), "-")
+	if len(parts) != 4 || parts[0] != "vote" {
+		return nil, fmt.Errorf("invalid vote format: expected vote-TEAM-ROW-COL, got %s", cmdStr)
+	}
+
+	var team game.Player
+	switch parts[1] {
+	case "x":
+		team = game.PlayerX
+	case "o":
+		team = game.PlayerO
+	default:
+		return nil, fmt.Errorf("invalid team: %s (must be x or o)", parts[1])
+	}
+
+	row, err := strconv.Atoi(parts[2])
+	if err != nil || row < 0 {
+		return nil, fmt.Errorf("invalid row: %s", parts[2])
+	}
+	col, err := strconv.Atoi(parts[3])
+	if err != nil || col < 0 {
+		return nil, fmt.Errorf("invalid col: %s", parts[3])
+	}
+	return &VoteParams{Team: team, Row: row, Col: col}, nil
+}
+
 // Query represents a parsed DNS query
 type Query struct {
 	SessionID   SessionID
 	PlayerToken game.PlayerToken
 	Command     Command
 	MoveParams  *MoveParams
-	RawQuery    string
+	// BotDifficulty is the level requested by a join-bot command
+	BotDifficulty game.Difficulty
+	// ReplayMove is the move number requested by a replay command, -1 if it could not be parsed
+	ReplayMove int
+	// FirstMove is the rule requested by a first command, empty if it could not be parsed
+	FirstMove game.FirstMove
+	// Vote is the vote cast by a vote command, nil if it could not be parsed
+	Vote     *VoteParams
+	RawQuery string
 }
 
 // IsSessionManagement returns true if the query is a session management command
@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"dns-tic-tac-toe/pkg/game"
+)
+
+func TestParseCreateOptions(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want game.SessionConfig
+	}{
+		{"new", game.SessionConfig{}},
+		{"create", game.SessionConfig{}},
+		{"new-4x4-3", game.SessionConfig{Board: game.BoardConfig{Rows: 4, Cols: 4, WinLength: 3}}},
+		{"NEW-15X15-5", game.SessionConfig{Board: game.BoardConfig{Rows: 15, Cols: 15, WinLength: 5}}},
+		{"new-connect4", game.SessionConfig{Variant: game.VariantConnectFour}},
+		{"new-c4-misere", game.SessionConfig{Variant: game.VariantConnectFour, Misere: true}},
+		{"new-uttt", game.SessionConfig{Variant: game.VariantUltimate}},
+		{"new-quantum", game.SessionConfig{Variant: game.VariantQuantum}},
+		{"new-fog", game.SessionConfig{Variant: game.VariantFog}},
+		{"new-notakto", game.SessionConfig{Variant: game.VariantNotakto}},
+		{"new-notakto-3", game.SessionConfig{Variant: game.VariantNotakto, Boards: 3}},
+		{"new-crowd", game.SessionConfig{Crowd: true}},
+		{"new-crowd-20", game.SessionConfig{Crowd: true, VotingWindow: 20 * time.Second}},
+		{"new-9x9-4-players-3", game.SessionConfig{Board: game.BoardConfig{Rows: 9, Cols: 9, WinLength: 4}, Players: 3}},
+		{"new-players-4-symbols-ab", game.SessionConfig{Players: 4, Symbols: []game.Player{"A", "B"}}},
+		{"new-vs-ai", game.SessionConfig{Bot: game.DifficultyMedium}},
+		{"new-vs-ai-hard", game.SessionConfig{Bot: game.DifficultyHard}},
+		{"new-vs-ai-bo5", game.SessionConfig{Bot: game.DifficultyMedium, BestOf: 5}},
+		{"new-blitz-60", game.SessionConfig{TimeControl: time.Minute}},
+		{"new-first-random", game.SessionConfig{FirstMove: game.FirstMoveCoinToss}},
+		{"new-first-loser", game.SessionConfig{FirstMove: game.FirstMoveLoser}},
+		{"new-from-xo_x_o___-o", game.SessionConfig{StartPosition: "xo_x_o___-o"}},
+		{"new-5x5-4-from-c3-b2", game.SessionConfig{Board: game.BoardConfig{Rows: 5, Cols: 5, WinLength: 4}, StartPosition: "c3-b2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			opts, err := ParseCreateOptions(tt.cmd)
+			if err != nil {
+				t.Fatalf("ParseCreateOptions(%q): %v", tt.cmd, err)
+			}
+			var got game.SessionConfig
+			for _, opt := range opts {
+				opt(&got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCreateOptions(%q) set %+v, want %+v", tt.cmd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCreateOptionsErrors(t *testing.T) {
+	tests := []string{
+		"newgame",
+		"new-chess",
+		"new-4x4",
+		"new-4xfour-3",
+		"new-4x4-three",
+		"new-players",
+		"new-players-many",
+		"new-symbols",
+		"new-vs",
+		"new-vs-human",
+		"new-blitz",
+		"new-blitz-0",
+		"new-crowd-0",
+		"new-first",
+		"new-first-winner",
+		"new-from",
+		"new-bo0",
+		"new-bofive",
+	}
+	for _, cmd := range tests {
+		t.Run(cmd, func(t *testing.T) {
+			_, err := ParseCreateOptions(cmd)
+			var dnsErr *Error
+			if !errors.As(err, &dnsErr) || dnsErr.Code != ErrCodeInvalidOptions {
+				t.Errorf("ParseCreateOptions(%q) = %v, want an invalid create options error", cmd, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Difficulty represents how strongly a bot opponent plays
+type Difficulty string
+
+const (
+	// DifficultyEasy plays a random legal move
+	DifficultyEasy Difficulty = "easy"
+	// DifficultyMedium looks two plies ahead using a heuristic evaluation
+	DifficultyMedium Difficulty = "medium"
+	// DifficultyHard searches with alpha-beta minimax, which plays perfectly on boards small enough to search fully
+	DifficultyHard Difficulty = "hard"
+)
+
+// ParseDifficulty parses a difficulty level ("perfect" is accepted as an alias for hard)
+func ParseDifficulty(level string) (Difficulty, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "easy":
+		return DifficultyEasy, nil
+	case "medium", "":
+		return DifficultyMedium, nil
+	case "hard", "perfect":
+		return DifficultyHard, nil
+	default:
+		return "", NewInvalidDifficultyError(level)
+	}
+}
+
+// Search limits for the bot
+const (
+	// mediumSearchDepth is how many plies the medium bot looks ahead
+	mediumSearchDepth = 2
+	// maxSearchTime bounds the hard bot's search so a move is always answered within one DNS query
+	maxSearchTime = 400 * time.Millisecond
+	// winScore is the score of a won position, large enough to dominate any heuristic yet within a 32-bit int
+	winScore = 1 << 29
+	// maxHeuristic bounds the line-counting heuristic, so it never reaches the scores of won positions
+	maxHeuristic = winScore / 4
+	// neighborhoodThreshold is the number of legal moves above which search only considers cells next to existing marks
+	neighborhoodThreshold = 25
+)
+
+// Bot is a server-side player that fills a seat in a session
+type Bot struct {
+	Player     Player     `json:"player"`
+	Difficulty Difficulty `json:"difficulty"`
+}
+
+// NewBot creates a new bot playing as player at the given difficulty
+func NewBot(player Player, difficulty Difficulty) *Bot {
+	return &Bot{
+		Player:     player,
+		Difficulty: difficulty,
+	}
+}
+
+// String returns a human-readable description of the bot
+func (b *Bot) String() string {
+	return fmt.Sprintf("%s bot (%s)", b.Difficulty, b.Player)
+}
+
+// searchable is implemented by engines the bot can play: it lists the legal moves in the
+// current position and copies the engine so moves can be tried without touching the game
+type searchable interface {
+	Engine
+
+	// legalMoves returns every legal move for the player to move
+	legalMoves() []Position
+
+	// snapshot returns an independent copy of the engine
+	snapshot() searchable
+
+	// rawState returns the engine's state without copying; only safe on snapshots
+	rawState() *GameState
+}
+
+// ChooseMove picks the bot's next move in the engine's current position
+func (b *Bot) ChooseMove(engine Engine) (Position, error) {
+	g, ok := engine.(searchable)
+	if !ok {
+		return Position{}, NewBotUnsupportedError(engine.GetState().Variant)
+	}
+
+	sim := g.snapshot()
+	state := sim.rawState()
+	if state.Status != StatusPlaying {
+		return Position{}, NewGameOverError(state.Status)
+	}
+	if state.Turn != b.Player {
+		return Position{}, NewWrongTurnError(b.Player, state.Turn)
+	}
+
+	moves := sim.legalMoves()
+	if len(moves) == 0 {
+		return Position{}, NewGameOverError(state.Status)
+	}
+
+	switch b.Difficulty {
+	case DifficultyEasy:
+		return moves[rand.Intn(len(moves))], nil
+	case DifficultyMedium:
+		move, _ := b.searchRoot(sim, mediumSearchDepth, nil)
+		return move, nil
+	default:
+		return b.searchDeepening(sim), nil
+	}
+}
+
+// searchDeepening runs alpha-beta search one ply deeper at a time until the position is
+// searched to the end or the time budget runs out, and returns the best fully searched move
+func (b *Bot) searchDeepening(g searchable) Position {
+	deadline := time.Now().Add(maxSearchTime)
+	moves := searchMoves(g)
+	best := moves[0]
+	for depth := 1; depth <= countEmpty(g.rawState().Board); depth++ {
+		move, ok := b.searchRoot(g, depth, &deadline)
+		if !ok {
+			break
+		}
+		best = move
+	}
+	return best
+}
+
+// searchRoot scores every candidate move to the given depth and returns the best one,
+// choosing randomly between equally good moves. Returns false if the deadline passed.
+func (b *Bot) searchRoot(g searchable, depth int, deadline *time.Time) (Position, bool) {
+	moves := searchMoves(g)
+	bestScore := -winScore - 1
+	var best []Position
+	for _, move := range moves {
+		child := g.snapshot()
+		if err := child.MakeMove(move.Row, move.Col, b.Player); err != nil {
+			continue
+		}
+		// Moves are only compared against the best so far, so the window starts just below it
+		// (ties still get an exact score and stay eligible for the random choice)
+		score, ok := negamax(child, depth-1, 1, -winScore-1, -(bestScore - 1), otherPlayer(b.Player), deadline)
+		if !ok {
+			return Position{}, false
+		}
+		score = -score
+		if score > bestScore {
+			bestScore = score
+			best = []Position{move}
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+	if len(best) == 0 {
+		return moves[0], true
+	}
+	return best[rand.Intn(len(best))], true
+}
+
+// negamax scores the position from the point of view of player (the player to move) using
+// alpha-beta pruning. ply is the distance from the root, so faster wins score higher.
+// Returns false if the deadline passed; a nil deadline searches without a time limit.
+func negamax(g searchable, depth, ply, alpha, beta int, player Player, deadline *time.Time) (int, bool) {
+	state := g.rawState()
+	if state.Status != StatusPlaying || depth == 0 {
+		return evaluate(state, player, ply), true
+	}
+
+	if deadline != nil && time.Now().After(*deadline) {
+		return 0, false
+	}
+
+	best := -winScore - 1
+	for _, move := range searchMoves(g) {
+		child := g.snapshot()
+		if err := child.MakeMove(move.Row, move.Col, player); err != nil {
+			continue
+		}
+		score, ok := negamax(child, depth-1, ply+1, -beta, -alpha, otherPlayer(player), deadline)
+		if !ok {
+			return 0, false
+		}
+		score = -score
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best, true
+}
+
+// searchMoves returns the legal moves worth searching, ordered from the center outwards
+// so alpha-beta prunes early. On large boards only cells next to existing marks are kept.
+func searchMoves(g searchable) []Position {
+	state := g.rawState()
+	moves := g.legalMoves()
+	if len(moves) > neighborhoodThreshold {
+		nearby := make([]Position, 0, len(moves))
+		for _, move := range moves {
+			if hasNeighbor(state.Board, move) {
+				nearby = append(nearby, move)
+			}
+		}
+		if len(nearby) > 0 {
+			moves = nearby
+		}
+	}
+
+	return orderFromCenter(moves, state)
+}
+
+// orderFromCenter sorts moves from the center of the board outwards
+func orderFromCenter(moves []Position, state *GameState) []Position {
+	centerRow, centerCol := float64(state.Rows-1)/2, float64(state.Cols-1)/2
+	distance := func(p Position) float64 {
+		dr, dc := float64(p.Row)-centerRow, float64(p.Col)-centerCol
+		return dr*dr + dc*dc
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return distance(moves[i]) < distance(moves[j])
+	})
+	return moves
+}
+
+// hasNeighbor reports whether any cell adjacent to p holds a mark
+func hasNeighbor(board [][]Player, p Position) bool {
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			row, col := p.Row+dr, p.Col+dc
+			if row >= 0 && row < len(board) && col >= 0 && col < len(board[row]) && board[row][col] != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluate scores the state from player's point of view: finished games score ±winScore
+// (less the ply, so faster wins are preferred), unfinished ones use a line-counting heuristic
+func evaluate(state *GameState, player Player, ply int) int {
+	if state.Status.IsOver() {
+		switch state.Status.Winner() {
+		case player:
+			return winScore - ply
+		case "":
+			return 0
+		default:
+			return -winScore + ply
+		}
+	}
+
+	switch {
+	case state.Ultimate != nil:
+		return evaluateUltimate(state.Ultimate, player)
+	case state.Notakto != nil:
+		// Both players place the same mark, so there are no lines to count
+		return 0
+	case state.Misere:
+		// Open lines are a liability when completing one loses
+		return -windowScore(state.Board, player, state.WinLength)
+	}
+	return windowScore(state.Board, player, state.WinLength)
+}
+
+// evaluateUltimate scores an ultimate position: open lines on the meta-board weigh far more
+// than open lines inside the local boards
+func evaluateUltimate(ultimate *UltimateState, player Player) int {
+	score := 0
+	meta := newBoard(3, 3)
+	for board, local := range ultimate.LocalBoards {
+		switch ultimate.MetaBoard[board/3][board%3] {
+		case StatusPlaying:
+			score += windowScore(local, player, 3)
+		case winStatus(player):
+			meta[board/3][board%3] = player
+		case winStatus(otherPlayer(player)):
+			meta[board/3][board%3] = otherPlayer(player)
+		}
+	}
+	score += 100 * windowScore(meta, player, 3)
+	return max(-maxHeuristic, min(score, maxHeuristic))
+}
+
+// windowScore sums every line of k cells that only one player has marks in,
+// weighting lines by how many marks they already hold
+func windowScore(board [][]Player, player Player, k int) int {
+	score := 0
+	for row := range board {
+		for col := range board[row] {
+			for _, dir := range lineDirections {
+				endRow, endCol := row+dir[0]*(k-1), col+dir[1]*(k-1)
+				if endRow < 0 || endRow >= len(board) || endCol < 0 || endCol >= len(board[row]) {
+					continue
+				}
+				mine, theirs := 0, 0
+				for i := 0; i < k; i++ {
+					switch board[row+dir[0]*i][col+dir[1]*i] {
+					case player:
+						mine++
+					case "":
+					default:
+						theirs++
+					}
+				}
+				if mine > 0 && theirs == 0 {
+					score += lineWeight(mine)
+				} else if theirs > 0 && mine == 0 {
+					score -= lineWeight(theirs)
+				}
+				// Clamp as we go, so long lines on large boards cannot overflow the sum
+				score = max(-maxHeuristic, min(score, maxHeuristic))
+			}
+		}
+	}
+	return score
+}
+
+// lineWeight grows with the number of marks in an open line, up to 4^12
+func lineWeight(marks int) int {
+	weight := 1
+	for i := 1; i < marks && i < 13; i++ {
+		weight *= 4
+	}
+	return weight
+}
+
+// countEmpty returns the number of empty cells on the board
+func countEmpty(board [][]Player) int {
+	empty := 0
+	for _, row := range board {
+		for _, cell := range row {
+			if cell == "" {
+				empty++
+			}
+		}
+	}
+	return empty
+}
+
+// otherPlayer returns the opponent of player
+func otherPlayer(player Player) Player {
+	if player == PlayerX {
+		return PlayerO
+	}
+	return PlayerX
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timed is implemented by engines that support per-player game clocks
+type Timed interface {
+	// SetTimeControl gives each player budget of thinking time per game, 0 to play untimed
+	// Takes effect from the next Reset (or immediately if the game has not started)
+	SetTimeControl(budget time.Duration)
+}
+
+// ClockState holds the game clocks of a timed game
+// Only the clock of the player to move runs, and only while the game is in progress
+type ClockState struct {
+	// InitialMs is each player's time budget for the game, in milliseconds
+	InitialMs int64 `json:"initial_ms"`
+	// RemainingMs is each player's time left, in milliseconds, as of TurnStarted
+	RemainingMs map[Player]int64 `json:"remaining_ms"`
+	// TurnStarted is when the running clock was last settled
+	TurnStarted time.Time `json:"turn_started"`
+}
+
+// clone returns a deep copy of the clocks
+func (c *ClockState) clone() *ClockState {
+	if c == nil {
+		return nil
+	}
+	clockCopy := *c
+	clockCopy.RemainingMs = make(map[Player]int64, len(c.RemainingMs))
+	for player, ms := range c.RemainingMs {
+		clockCopy.RemainingMs[player] = ms
+	}
+	return &clockCopy
+}
+
+// newClockState creates full clocks for both players, or nil for an untimed game
+func newClockState(budget time.Duration) *ClockState {
+	if budget <= 0 {
+		return nil
+	}
+	return &ClockState{
+		InitialMs:   budget.Milliseconds(),
+		RemainingMs: map[Player]int64{PlayerX: budget.Milliseconds(), PlayerO: budget.Milliseconds()},
+	}
+}
+
+// timeoutStatus returns the status of a game player lost on time
+func timeoutStatus(player Player) Status {
+	if player == PlayerX {
+		return StatusXTimeout
+	}
+	return StatusOTimeout
+}
+
+// SetTimeControl gives each player budget of thinking time per game, 0 to play untimed
+func (b *baseEngine) SetTimeControl(budget time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timeControl = budget
+	if b.state.Status == StatusPending {
+		b.resetClock()
+	}
+}
+
+// resetClock gives both players a full clock for a new game
+// Must be called with the lock held
+func (b *baseEngine) resetClock() {
+	b.state.Clock = newClockState(b.timeControl)
+}
+
+// startClock starts the clock of the player to move
+// Must be called with the lock held
+func (b *baseEngine) startClock() {
+	if b.state.Clock != nil {
+		b.state.Clock.TurnStarted = time.Now()
+	}
+}
+
+// tickClock charges the time since the running clock was last settled to the player to move,
+// and ends the game when their time runs out
+// Must be called with the lock held
+func (b *baseEngine) tickClock() {
+	clock := b.state.Clock
+	if clock == nil || b.state.Status != StatusPlaying {
+		return
+	}
+
+	// Only whole milliseconds are charged, the rest carries over to the next tick
+	player := b.state.Turn
+	elapsed := time.Since(clock.TurnStarted).Milliseconds()
+	clock.RemainingMs[player] -= elapsed
+	clock.TurnStarted = clock.TurnStarted.Add(time.Duration(elapsed) * time.Millisecond)
+	if clock.RemainingMs[player] <= 0 {
+		clock.RemainingMs[player] = 0
+		b.endGame(timeoutStatus(player))
+	}
+}
+
+// writeClockLine writes each player's remaining time, marking the running clock
+func writeClockLine(sb *strings.Builder, state *GameState) {
+	clock := state.Clock
+	if clock == nil {
+		return
+	}
+	parts := make([]string, 0, len(seats))
+	for _, player := range seats {
+		part := fmt.Sprintf("%s %s", player, formatClock(clock.RemainingMs[player]))
+		if state.Status == StatusPlaying && state.Turn == player {
+			part += " (running)"
+		}
+		parts = append(parts, part)
+	}
+	sb.WriteString(fmt.Sprintf("Clock: %s\n", strings.Join(parts, " | ")))
+}
+
+// formatClock formats milliseconds as M:SS, rounding up so a clock only shows 0:00 once it has run out
+func formatClock(ms int64) string {
+	seconds := (ms + 999) / 1000
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
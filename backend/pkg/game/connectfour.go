@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConnectFourBoardConfig is the standard Connect Four board: 7 columns, 6 rows, four in a row wins
+var ConnectFourBoardConfig = BoardConfig{Rows: 6, Cols: 7, WinLength: 4}
+
+// ColumnDropper is implemented by engines where pieces are dropped into a column
+// and fall to the lowest free row
+type ColumnDropper interface {
+	// DropPiece drops the player's piece into the given column
+	// Returns an error if the column is full or the move is invalid
+	DropPiece(col int, player Player) error
+}
+
+// ConnectFour implements the Engine interface for Connect Four
+// It is an m,n,k game with gravity: row 0 is the top of the grid and pieces fall towards the last row
+type ConnectFour struct {
+	*TicTacToe
+}
+
+// NewConnectFour creates a new Connect Four game on the standard 7x6 board
+func NewConnectFour() *ConnectFour {
+	game, _ := NewConnectFourWithBoard(ConnectFourBoardConfig)
+	return game
+}
+
+// NewConnectFourWithBoard creates a new Connect Four game with the given board configuration
+func NewConnectFourWithBoard(config BoardConfig) (*ConnectFour, error) {
+	base, err := NewTicTacToeWithBoard(config)
+	if err != nil {
+		return nil, err
+	}
+	base.variant = VariantConnectFour
+	base.state.Variant = VariantConnectFour
+	return &ConnectFour{TicTacToe: base}, nil
+}
+
+// MakeMove attempts to make a move at the specified position
+// Pieces cannot float, so row must be the lowest free row of col (see DropPiece)
+func (g *ConnectFour) MakeMove(row, col int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+
+	landing, err := g.landingRow(col)
+	if err != nil {
+		return err
+	}
+	if row != landing {
+		return NewFloatingPieceError(row, col, landing)
+	}
+
+	return g.placeMark(landing, col, player)
+}
+
+// DropPiece drops the player's piece into col, where it lands on the lowest free row
+func (g *ConnectFour) DropPiece(col int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+
+	row, err := g.landingRow(col)
+	if err != nil {
+		return err
+	}
+
+	return g.placeMark(row, col, player)
+}
+
+// landingRow returns the lowest free row in col
+// Must be called with the lock held
+func (g *ConnectFour) landingRow(col int) (int, error) {
+	if col < 0 || col >= g.state.Cols {
+		return 0, NewInvalidColumnError(col, g.state.Cols)
+	}
+
+	for row := g.state.Rows - 1; row >= 0; row-- {
+		if g.state.Board[row][col] == "" {
+			return row, nil
+		}
+	}
+
+	return 0, NewColumnFullError(col)
+}
+
+// legalMoves returns the landing cell of every column that is not full
+func (g *ConnectFour) legalMoves() []Position {
+	if g.state.Status != StatusPlaying {
+		return nil
+	}
+	var moves []Position
+	for col := 0; col < g.state.Cols; col++ {
+		if row, err := g.landingRow(col); err == nil {
+			moves = append(moves, Position{Row: row, Col: col})
+		}
+	}
+	return moves
+}
+
+// snapshot returns an independent copy of the game
+func (g *ConnectFour) snapshot() searchable {
+	return &ConnectFour{TicTacToe: g.copyGame()}
+}
+
+// FormatBoard returns a human-readable string representation of the board
+// Columns are numbered above the grid since moves are addressed by column only
+func (g *ConnectFour) FormatBoard() string {
+	state := g.GetState()
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for j := 0; j < state.Cols; j++ {
+		sb.WriteString(fmt.Sprintf("%d", j%10))
+		if j < state.Cols-1 {
+			sb.WriteString(" ")
+		}
+	}
+	sb.WriteString("\n")
+	writeGrid(&sb, state.Board, state.WinningLine)
+	if state.Misere {
+		sb.WriteString(fmt.Sprintf("Connect Four, misère: %d in a row loses\n", state.WinLength))
+	} else {
+		sb.WriteString(fmt.Sprintf("Connect Four: %d in a row wins\n", state.WinLength))
+	}
+	writeStatusLines(&sb, state)
+	return sb.String()
+}
@@ -0,0 +1,253 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultVotingWindow is how long a crowd-play voting round stays open when neither the session
+// nor the manager sets a window
+const DefaultVotingWindow = 30 * time.Second
+
+// CrowdVotes is the tally of the current voting round of a crowd-play session
+type CrowdVotes struct {
+	// Team is the side whose move is being voted on
+	Team Player `json:"team"`
+	// Move is the number the chosen move will have
+	Move int `json:"move"`
+	// WindowMs is how long a round stays open after its first vote
+	WindowMs int64 `json:"window_ms"`
+	// ClosesAt is when the most-voted move is played, nil until the round's first vote
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+	// Votes holds every cell voted for, most votes first (ties go to the cell voted for first)
+	Votes []VoteCount `json:"votes"`
+	Total int         `json:"total"`
+}
+
+// VoteCount is the number of votes for one cell
+type VoteCount struct {
+	Position
+	Votes int `json:"votes"`
+}
+
+// String returns the tally on up to three lines, e.g.
+// "Team X to play move 5, voting closes in 12s", "1-1 (3), 0-0 (1)" and "Total: 4 vote(s)"
+func (v *CrowdVotes) String() string {
+	text := fmt.Sprintf("Team %s to play move %d", v.Team, v.Move)
+	if v.ClosesAt == nil {
+		return text + fmt.Sprintf(", no votes yet (voting closes %s after the first vote)", time.Duration(v.WindowMs)*time.Millisecond)
+	}
+	text += fmt.Sprintf(", voting closes in %s", time.Until(*v.ClosesAt).Round(time.Second))
+	for i, vote := range v.Votes {
+		if i == 0 {
+			text += "\n"
+		} else {
+			text += ", "
+		}
+		text += fmt.Sprintf("%s (%d)", vote.Position, vote.Votes)
+	}
+	return text + fmt.Sprintf("\nTotal: %d vote(s)", v.Total)
+}
+
+// votingRound holds the votes cast for one move of a crowd-play session
+type votingRound struct {
+	window   time.Duration
+	team     Player
+	move     int
+	closesAt time.Time        // zero until the first vote
+	tally    map[Position]int // votes per cell
+	order    []Position       // cells in the order they got their first vote, for breaking ties
+}
+
+// open starts a new round for team's move number move, dropping any earlier votes
+func (r *votingRound) open(team Player, move int) {
+	r.team = team
+	r.move = move
+	r.closesAt = time.Time{}
+	r.tally = make(map[Position]int)
+	r.order = nil
+}
+
+// ranked returns the cells voted for, most votes first
+func (r *votingRound) ranked() []VoteCount {
+	votes := make([]VoteCount, len(r.order))
+	for i, cell := range r.order {
+		votes[i] = VoteCount{Position: cell, Votes: r.tally[cell]}
+	}
+	sort.SliceStable(votes, func(i, j int) bool { return votes[i].Votes > votes[j].Votes })
+	return votes
+}
+
+// summary returns the round as CrowdVotes
+func (r *votingRound) summary() *CrowdVotes {
+	votes := &CrowdVotes{
+		Team:     r.team,
+		Move:     r.move,
+		WindowMs: r.window.Milliseconds(),
+		Votes:    r.ranked(),
+	}
+	for _, vote := range votes.Votes {
+		votes.Total += vote.Votes
+	}
+	if !r.closesAt.IsZero() {
+		closesAt := r.closesAt
+		votes.ClosesAt = &closesAt
+	}
+	return votes
+}
+
+// clearVotes drops the votes of the current round, e.g. when the board is reset
+// Must be called with the lock held
+func (s *Session) clearVotes() {
+	if s.crowd != nil {
+		s.crowd.open("", 0)
+	}
+}
+
+// crowdPlayable reports whether a crowd can play engine by voting for single cells
+// Games with hidden information or two-cell moves cannot be voted on
+func crowdPlayable(engine Engine) bool {
+	switch engine.(type) {
+	case PlayerViewer, QuantumMover:
+		return false
+	}
+	return true
+}
+
+// isLegalMove reports whether the player to move may play at (row, col)
+// Engines that cannot list their legal moves only get the bounds and free-cell checks
+func isLegalMove(engine Engine, row, col int) bool {
+	g, ok := engine.(searchable)
+	if !ok {
+		return true
+	}
+	for _, move := range g.snapshot().legalMoves() {
+		if move.Row == row && move.Col == col {
+			return true
+		}
+	}
+	return false
+}
+
+// seatTeams fills every free seat with a team the audience votes for, without a token anyone knows
+// Must be called with the lock held (or before the session is shared)
+func (s *Session) seatTeams() {
+	for {
+		team, ok := s.freeSeat()
+		if !ok {
+			break
+		}
+		s.Players[s.generateToken()] = team
+		s.publish(Event{Type: EventPlayerJoined, Player: team})
+	}
+	s.Game.StartGame()
+}
+
+// IsCrowd reports whether the session's moves are chosen by audience votes
+func (s *Session) IsCrowd() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.crowd != nil
+}
+
+// Vote casts a vote for team to play at (row, col) and returns the round's tally
+// The first vote of a round starts its voting window; votes only count for the team to move
+// and for cells that are free right now
+func (s *Session) Vote(team Player, row, col int) (*CrowdVotes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.crowd == nil {
+		return nil, NewNotCrowdSessionError()
+	}
+	state := s.Game.GetState()
+	switch {
+	case state.Status == StatusPending:
+		return nil, NewGameNotStartedError()
+	case state.Status.IsOver():
+		return nil, NewGameOverError(state.Status)
+	case team != state.Turn:
+		return nil, NewWrongTeamError(team, state.Turn)
+	case row < 0 || row >= state.Rows || col < 0 || col >= state.Cols:
+		return nil, NewInvalidPositionError(row, col, state.Rows, state.Cols)
+	case state.Board[row][col] != "":
+		return nil, ErrPositionTaken
+	case !isLegalMove(s.Game, row, col):
+		return nil, NewIllegalVoteError(Position{Row: row, Col: col})
+	}
+
+	round := s.currentRound(state)
+	cell := Position{Row: row, Col: col}
+	if round.closesAt.IsZero() {
+		round.closesAt = time.Now().Add(round.window)
+	}
+	if round.tally[cell] == 0 {
+		round.order = append(round.order, cell)
+	}
+	round.tally[cell]++
+	s.touch()
+	return round.summary(), nil
+}
+
+// Votes returns the tally of the current voting round
+func (s *Session) Votes() (*CrowdVotes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.crowd == nil {
+		return nil, NewNotCrowdSessionError()
+	}
+	return s.currentRound(s.Game.GetState()).summary(), nil
+}
+
+// currentRound returns the voting round for the move to play in state, opening a new one
+// if the votes held are for an earlier move
+// Must be called with the lock held
+func (s *Session) currentRound(state *GameState) *votingRound {
+	move := len(state.Moves) + 1
+	if s.crowd.team != state.Turn || s.crowd.move != move {
+		s.crowd.open(state.Turn, move)
+	}
+	return s.crowd
+}
+
+// closeVotingRound plays the most-voted legal move once the round's window has passed
+// Returns true if a move was played
+func (s *Session) closeVotingRound(now time.Time) bool {
+	s.mu.Lock()
+	round := s.crowd
+	if round == nil || round.closesAt.IsZero() || now.Before(round.closesAt) {
+		s.mu.Unlock()
+		return false
+	}
+	votes := round.summary()
+	round.open("", 0)
+
+	// A cell may have been taken since it was voted for, so fall back to the next most-voted
+	played := false
+	for _, vote := range votes.Votes {
+		if err := s.Game.MakeMove(vote.Row, vote.Col, votes.Team); err != nil {
+			continue
+		}
+		if annotated, ok := s.Game.(moveAnnotator); ok {
+			annotated.annotateLastMove(votes.Move, fmt.Sprintf("chosen by %d of %d crowd votes", vote.Votes, votes.Total))
+		}
+		played = true
+		break
+	}
+	s.mu.Unlock()
+
+	if played {
+		s.PlayBotTurn()
+	}
+	return played
+}
+
+// CloseVotingRounds plays the most-voted move of every crowd-play session whose voting window has passed
+func (m *Manager) CloseVotingRounds() {
+	now := time.Now()
+	for _, session := range m.store.List() {
+		session.closeVotingRound(now)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Engine defines the interface for a tic-tac-toe game engine
@@ -27,32 +28,402 @@ type Engine interface {
 
 	// StartGame sets the game status to playing (called when both players have joined)
 	StartGame()
+
+	// RequestUndo records player's request to take back their last move, pending the opponent's consent
+	RequestUndo(player Player) error
+
+	// AcceptUndo accepts the opponent's pending takeback request, rolling back their last move
+	// and any reply played since. Returns the number of moves undone.
+	AcceptUndo(player Player) (int, error)
+
+	// Resign ends the game with a win for player's opponent
+	Resign(player Player) error
+
+	// OfferDraw records player's draw offer, pending the opponent's consent
+	OfferDraw(player Player) error
+
+	// AcceptDraw accepts the opponent's pending draw offer, ending the game in a draw
+	AcceptDraw(player Player) error
+
+	// Forfeit ends a two-player game with a loss for player, recording reason in the state
+	Forfeit(player Player, reason string) error
+}
+
+// baseEngine holds the state and lock shared by all engine implementations
+// Variants embed it and provide MakeMove, Reset and FormatBoard
+type baseEngine struct {
+	state       *GameState
+	start       *GameState    // position loaded at creation that new games start from, nil for the empty board
+	timeControl time.Duration // each player's clock budget, 0 for untimed games
+	firstPlayer Player        // player who moves first in new games, "" for X
+	observe     func(Event)   // receives the engine's events, nil when nobody listens
+	replaying   bool          // set while rebuilding a position from moves that were already reported
+	mu          sync.RWMutex
+}
+
+// GetState returns the current game state (thread-safe copy)
+func (b *baseEngine) GetState() *GameState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Settle the running clock so the copy shows the time left right now
+	b.tickClock()
+	// Return a copy to prevent external modification
+	return b.state.Clone()
+}
+
+// cloneForSearch copies the state for simulated play, without clocks so simulated moves never run out of time
+// Must be called with the lock held
+func (b *baseEngine) cloneForSearch() *GameState {
+	state := b.state.Clone()
+	state.Clock = nil
+	return state
 }
 
-// TicTacToe implements the Engine interface
+// StartGame sets the game status to playing (called when both players have joined)
+func (b *baseEngine) StartGame() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Only start if currently pending
+	if b.state.Status == StatusPending {
+		b.state.Status = StatusPlaying
+		b.state.TurnStarted = time.Now()
+		b.startClock()
+		b.emit(Event{Type: EventGameStarted, Player: b.state.Turn})
+	}
+}
+
+// GetStateJSON returns the game state as a JSON string
+func (b *baseEngine) GetStateJSON() string {
+	state := b.GetState()
+	jsonData, _ := json.Marshal(state)
+	return string(jsonData)
+}
+
+// checkCanMove checks that the game is in progress and it is player's turn
+// Must be called with the lock held
+func (b *baseEngine) checkCanMove(player Player) error {
+	b.tickClock()
+	if b.state.Status != StatusPlaying {
+		return NewGameOverError(b.state.Status)
+	}
+
+	if player != b.state.Turn {
+		return NewWrongTurnError(player, b.state.Turn)
+	}
+
+	return nil
+}
+
+// recordMove appends an accepted move to the history
+// Playing on declines any pending takeback request or draw offer
+// Must be called with the lock held, after the move has been applied
+func (b *baseEngine) recordMove(row, col int, player Player) {
+	b.appendMove(Move{Player: player, Row: row, Col: col})
+}
+
+// appendMove numbers, timestamps and records a move, clearing any pending offers
+// Must be called with the lock held
+func (b *baseEngine) appendMove(move Move) {
+	b.state.UndoRequest = ""
+	b.state.DrawOffer = ""
+	move.Number = len(b.state.Moves) + 1
+	move.Timestamp = time.Now()
+	b.state.Moves = append(b.state.Moves, move)
+	b.emit(Event{Type: EventMoveMade, Player: move.Player, Move: &move})
+}
+
+// replayer is implemented by variants so the base engine can rebuild a position from its move history
+type replayer interface {
+	// newState returns the variant's empty, pending state
+	newState() *GameState
+
+	// replayMove applies a recorded move to the current state
+	// Must be called with the lock held
+	replayMove(move Move) error
+}
+
+// RequestUndo records player's request to take back their last move, pending the opponent's consent
+func (b *baseEngine) RequestUndo(player Player) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state.Status == StatusPending {
+		return NewGameNotStartedError()
+	}
+	if b.lastMoveBy(player) < 0 {
+		return ErrNothingToUndo
+	}
+	if b.state.UndoRequest != "" {
+		return NewUndoPendingError(b.state.UndoRequest)
+	}
+
+	b.state.UndoRequest = player
+	return nil
+}
+
+// acceptUndo accepts the opponent's takeback request, replaying the history without the requester's
+// last move and any reply to it. Variants wrap it so r rebuilds the position with their rules.
+// Must be called with the lock held
+func (b *baseEngine) acceptUndo(r replayer, player Player) (int, error) {
+	b.tickClock()
+	requester := b.state.UndoRequest
+	if requester == "" {
+		return 0, ErrNoUndoRequest
+	}
+	if requester == player {
+		return 0, ErrOwnUndoRequest
+	}
+
+	count := len(b.state.Moves) - b.lastMoveBy(requester)
+	if err := b.rollback(r, count); err != nil {
+		return 0, err
+	}
+	b.emit(Event{Type: EventTakeback, Player: requester, Undone: count})
+	return count, nil
+}
+
+// rollback removes the last count moves by replaying the rest of the history on a fresh state
+// The remaining moves keep their original timestamps and the clocks are not wound back.
+// On failure the state is left untouched.
+// Must be called with the lock held
+func (b *baseEngine) rollback(r replayer, count int) error {
+	previous := b.state
+	if err := b.rebuild(r, previous.Moves[:len(previous.Moves)-count]); err != nil {
+		return err
+	}
+	b.state.Clock = previous.Clock
+	b.state.TurnStarted = time.Now()
+	b.startClock()
+	return nil
+}
+
+// rebuild replays moves on the game's initial state, keeping their original timestamps
+// On failure the state is left untouched.
+// Must be called with the lock held
+func (b *baseEngine) rebuild(r replayer, moves []Move) error {
+	b.replaying = true
+	defer func() { b.replaying = false }()
+
+	previous := b.state
+	b.state = b.initialState(r)
+	b.state.Status = StatusPlaying
+	// The game may have started under an earlier first-move setting than the one for the next game
+	switch {
+	case len(moves) > 0:
+		b.state.Turn = moves[0].Player
+		b.state.FirstPlayer = moves[0].Player
+	case b.start == nil && previous.FirstPlayer != "":
+		b.state.Turn = previous.FirstPlayer
+		b.state.FirstPlayer = previous.FirstPlayer
+	}
+	for _, move := range moves {
+		if err := r.replayMove(move); err != nil {
+			b.state = previous
+			return err
+		}
+	}
+	b.state.Moves = append([]Move(nil), moves...)
+	return nil
+}
+
+// Resign ends the game with a win for player's opponent
+func (b *baseEngine) Resign(player Player) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkInProgress(); err != nil {
+		return err
+	}
+	if len(b.state.Players) > 2 {
+		return NewMultiplayerUnsupportedError("resigning")
+	}
+
+	b.endGame(resignStatus(player))
+	return nil
+}
+
+// OfferDraw records player's draw offer, pending the opponent's consent
+func (b *baseEngine) OfferDraw(player Player) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkInProgress(); err != nil {
+		return err
+	}
+	if len(b.state.Players) > 2 {
+		return NewMultiplayerUnsupportedError("draw offers")
+	}
+	if b.state.DrawOffer != "" {
+		return NewDrawOfferPendingError(b.state.DrawOffer)
+	}
+
+	b.state.DrawOffer = player
+	return nil
+}
+
+// AcceptDraw accepts the opponent's pending draw offer, ending the game in a draw
+func (b *baseEngine) AcceptDraw(player Player) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkInProgress(); err != nil {
+		return err
+	}
+	switch b.state.DrawOffer {
+	case "":
+		return ErrNoDrawOffer
+	case player:
+		return ErrOwnDrawOffer
+	}
+
+	b.endGame(StatusDrawAgreed)
+	return nil
+}
+
+// Forfeit ends a two-player game with a loss for player, recording reason in the state
+func (b *baseEngine) Forfeit(player Player, reason string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkInProgress(); err != nil {
+		return err
+	}
+	if len(b.state.Players) > 2 {
+		return NewMultiplayerUnsupportedError("forfeits")
+	}
+
+	b.endGame(forfeitStatus(player))
+	b.state.EndReason = reason
+	return nil
+}
+
+// moveAnnotator is implemented by engines that can record why the server played a move for a player
+type moveAnnotator interface {
+	annotateLastMove(number int, reason string)
+}
+
+// annotateLastMove records why the server played the last move on the player's behalf
+// Does nothing if the last move is not move number
+func (b *baseEngine) annotateLastMove(number int, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.state.Moves) == number {
+		b.state.Moves[number-1].Reason = reason
+	}
+}
+
+// checkInProgress checks that the game has started and is not over
+// Must be called with the lock held
+func (b *baseEngine) checkInProgress() error {
+	b.tickClock()
+	switch b.state.Status {
+	case StatusPending:
+		return NewGameNotStartedError()
+	case StatusPlaying:
+		return nil
+	default:
+		return NewGameOverError(b.state.Status)
+	}
+}
+
+// endGame ends the game with status, dropping any pending request or offer
+// Must be called with the lock held
+func (b *baseEngine) endGame(status Status) {
+	b.state.Status = status
+	b.state.UndoRequest = ""
+	b.state.DrawOffer = ""
+	if b.state.Ultimate != nil {
+		b.state.Ultimate.ForcedBoard = -1
+	}
+	b.emit(Event{Type: EventGameOver, Player: status.Winner(), Status: status})
+}
+
+// lastMoveBy returns the index in the history of player's last move, or -1 if they have not moved
+// Must be called with the lock held
+func (b *baseEngine) lastMoveBy(player Player) int {
+	for i := len(b.state.Moves) - 1; i >= 0; i-- {
+		if b.state.Moves[i].Player == player {
+			return i
+		}
+	}
+	return -1
+}
+
+// switchTurn hands the turn to the next player
+// Must be called with the lock held
+func (b *baseEngine) switchTurn() {
+	b.state.Turn = nextPlayer(b.state.Players, b.state.Turn)
+	b.state.TurnStarted = time.Now()
+}
+
+// rawState returns the state without copying
+// Only safe on engines no other goroutine can see, such as bot search snapshots
+func (b *baseEngine) rawState() *GameState {
+	return b.state
+}
+
+// winStatus returns the status for a game won by player, e.g. StatusXWins for X
+func winStatus(player Player) Status {
+	return Status(string(player) + "_wins")
+}
+
+// resignStatus returns the status of a two-player game player resigned
+func resignStatus(player Player) Status {
+	if player == PlayerX {
+		return StatusXResigned
+	}
+	return StatusOResigned
+}
+
+// forfeitStatus returns the status of a two-player game player forfeited
+func forfeitStatus(player Player) Status {
+	if player == PlayerX {
+		return StatusXForfeited
+	}
+	return StatusOForfeited
+}
+
+// TicTacToe implements the Engine interface for m,n,k games:
+// an m x n board where k marks in a row (horizontally, vertically or diagonally) win
 type TicTacToe struct {
-	state *GameState
-	mu    sync.RWMutex
+	baseEngine
+	config  BoardConfig
+	variant Variant
+	misere  bool     // completing a line loses, see SetMisere
+	players []Player // turn order with more than two players, see SetPlayers
 }
 
-// NewTicTacToe creates a new tic-tac-toe game instance
+// NewTicTacToe creates a new classic 3x3 tic-tac-toe game instance
 func NewTicTacToe() *TicTacToe {
-	return &TicTacToe{
-		state: &GameState{
-			Board:  [3][3]Player{{"", "", ""}, {"", "", ""}, {"", "", ""}},
-			Turn:   PlayerX,
-			Status: StatusPending,
-		},
+	game, _ := NewTicTacToeWithBoard(DefaultBoardConfig)
+	return game
+}
+
+// NewTicTacToeWithBoard creates a new m,n,k game with the given board configuration
+func NewTicTacToeWithBoard(config BoardConfig) (*TicTacToe, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
+	g := &TicTacToe{config: config, variant: VariantTicTacToe}
+	g.state = g.newState()
+	return g, nil
 }
 
-// GetState returns the current game state (thread-safe copy)
-func (g *TicTacToe) GetState() *GameState {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	// Return a copy to prevent external modification
-	stateCopy := *g.state
-	return &stateCopy
+// newState returns an empty, pending state for the configured board
+func (g *TicTacToe) newState() *GameState {
+	return &GameState{
+		Variant:     g.variant,
+		Board:       newBoard(g.config.Rows, g.config.Cols),
+		Rows:        g.config.Rows,
+		Cols:        g.config.Cols,
+		WinLength:   g.config.WinLength,
+		Turn:        PlayerX,
+		FirstPlayer: PlayerX,
+		Status:      StatusPending,
+		Misere:      g.misere,
+		Players:     g.players,
+	}
 }
 
 // MakeMove attempts to make a move at the specified position
@@ -60,16 +431,17 @@ func (g *TicTacToe) MakeMove(row, col int, player Player) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	if g.state.Status != StatusPlaying {
-		return NewGameOverError(g.state.Status)
-	}
-
-	if player != g.state.Turn {
-		return NewWrongTurnError(player, g.state.Turn)
+	if err := g.checkCanMove(player); err != nil {
+		return err
 	}
+	return g.placeMark(row, col, player)
+}
 
-	if row < 0 || row >= 3 || col < 0 || col >= 3 {
-		return NewInvalidPositionError(row, col)
+// placeMark places player's mark at (row, col), then updates the status and turn
+// Must be called with the lock held, after checkCanMove
+func (g *TicTacToe) placeMark(row, col int, player Player) error {
+	if row < 0 || row >= g.state.Rows || col < 0 || col >= g.state.Cols {
+		return NewInvalidPositionError(row, col, g.state.Rows, g.state.Cols)
 	}
 
 	if g.state.Board[row][col] != "" {
@@ -77,48 +449,80 @@ func (g *TicTacToe) MakeMove(row, col int, player Player) error {
 	}
 
 	g.state.Board[row][col] = player
+	g.recordMove(row, col, player)
 
-	// Check for win
-	if g.checkWin(player) {
-		if player == PlayerX {
-			g.state.Status = StatusXWins
+	// Check for win (a loss under misère rules)
+	if line := g.checkWin(row, col, player); line != nil {
+		g.state.WinningLine = line
+		if g.misere {
+			g.endGame(completedLineStatus(player))
 		} else {
-			g.state.Status = StatusOWins
+			g.endGame(winStatus(player))
 		}
 	} else if g.isBoardFull() {
-		g.state.Status = StatusDraw
+		g.endGame(StatusDraw)
 	} else {
-		// Switch turn
-		if g.state.Turn == PlayerX {
-			g.state.Turn = PlayerO
-		} else {
-			g.state.Turn = PlayerX
-		}
+		g.switchTurn()
 	}
 
 	return nil
 }
 
-// Reset resets the game to its initial state
-func (g *TicTacToe) Reset() {
+// AcceptUndo accepts the opponent's pending takeback request and returns the number of moves undone
+func (g *TicTacToe) AcceptUndo(player Player) (int, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	// Reset to pending - the caller should call StartGame() if both players are still in
-	g.state = &GameState{
-		Board:  [3][3]Player{{"", "", ""}, {"", "", ""}, {"", "", ""}},
-		Turn:   PlayerX,
-		Status: StatusPending,
+	return g.acceptUndo(g, player)
+}
+
+// replayMove applies a recorded move to the current state
+// Must be called with the lock held
+func (g *TicTacToe) replayMove(move Move) error {
+	return g.placeMark(move.Row, move.Col, move.Player)
+}
+
+// legalMoves returns every empty cell while the game is in progress
+func (g *TicTacToe) legalMoves() []Position {
+	if g.state.Status != StatusPlaying {
+		return nil
+	}
+	var moves []Position
+	for row := range g.state.Board {
+		for col, cell := range g.state.Board[row] {
+			if cell == "" {
+				moves = append(moves, Position{Row: row, Col: col})
+			}
+		}
 	}
+	return moves
 }
 
-// StartGame sets the game status to playing (called when both players have joined)
-func (g *TicTacToe) StartGame() {
+// snapshot returns an independent copy of the game
+func (g *TicTacToe) snapshot() searchable {
+	return g.copyGame()
+}
+
+// copyGame copies the game's configuration and state under the read lock
+func (g *TicTacToe) copyGame() *TicTacToe {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return &TicTacToe{
+		baseEngine: baseEngine{state: g.cloneForSearch(), start: g.start, firstPlayer: g.firstPlayer},
+		config:     g.config,
+		variant:    g.variant,
+		misere:     g.misere,
+		players:    g.players,
+	}
+}
+
+// Reset resets the game to its initial state
+func (g *TicTacToe) Reset() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	// Only start if currently pending
-	if g.state.Status == StatusPending {
-		g.state.Status = StatusPlaying
-	}
+	// Reset to pending - the caller should call StartGame() if both players are still in
+	g.state = g.initialState(g)
+	g.resetClock()
+	g.emit(Event{Type: EventReset})
 }
 
 // FormatBoard returns a human-readable string representation of the board
@@ -126,68 +530,161 @@ func (g *TicTacToe) FormatBoard() string {
 	state := g.GetState()
 	var sb strings.Builder
 	sb.WriteString("\n")
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			cell := state.Board[i][j]
-			if cell == "" {
-				sb.WriteString("_")
-			} else {
-				sb.WriteString(string(cell))
-			}
-			if j < 2 {
-				sb.WriteString(" ")
-			}
-		}
-		sb.WriteString("\n")
+	writeGrid(&sb, state.Board, state.WinningLine)
+	switch {
+	case state.Misere:
+		sb.WriteString(fmt.Sprintf("Board: %dx%d, misère: %d in a row loses\n", state.Rows, state.Cols, state.WinLength))
+	case g.config != DefaultBoardConfig:
+		sb.WriteString(fmt.Sprintf("Board: %dx%d, %d in a row wins\n", state.Rows, state.Cols, state.WinLength))
 	}
-	sb.WriteString(fmt.Sprintf("Turn: %s | Status: %s\n", state.Turn, state.Status))
+	writeStatusLines(&sb, state)
 	return sb.String()
 }
 
-// GetStateJSON returns the game state as a JSON string
-func (g *TicTacToe) GetStateJSON() string {
-	state := g.GetState()
-	jsonData, _ := json.Marshal(state)
-	return string(jsonData)
+// checkWin checks if the mark player just placed at (row, col) completes WinLength marks in a row
+// Returns the cells of the completed line, or nil
+func (g *TicTacToe) checkWin(row, col int, player Player) []Position {
+	return lineThrough(g.state.Board, row, col, player, g.state.WinLength)
 }
 
-// checkWin checks if the specified player has won
-func (g *TicTacToe) checkWin(player Player) bool {
-	board := g.state.Board
+// isBoardFull checks if the board is completely filled
+func (g *TicTacToe) isBoardFull() bool {
+	return isFull(g.state.Board)
+}
 
-	// Check rows
-	for i := 0; i < 3; i++ {
-		if board[i][0] == player && board[i][1] == player && board[i][2] == player {
-			return true
+// isFull checks if every cell of the board is filled
+func isFull(board [][]Player) bool {
+	for i := range board {
+		for j := range board[i] {
+			if board[i][j] == "" {
+				return false
+			}
 		}
 	}
+	return true
+}
 
-	// Check columns
-	for i := 0; i < 3; i++ {
-		if board[0][i] == player && board[1][i] == player && board[2][i] == player {
-			return true
+// writeStatusLines writes the turn order of games with more than two players, any pending request or offer,
+// the winning line and the clocks, followed by the turn and status
+func writeStatusLines(sb *strings.Builder, state *GameState) {
+	if len(state.Players) > 2 {
+		sb.WriteString(fmt.Sprintf("Players: %s (in turn)\n", joinPlayers(state.Players)))
+	}
+	if state.UndoRequest != "" {
+		sb.WriteString(fmt.Sprintf("Takeback requested by %s\n", state.UndoRequest))
+	}
+	if state.DrawOffer != "" {
+		sb.WriteString(fmt.Sprintf("Draw offered by %s\n", state.DrawOffer))
+	}
+	if state.WinningLine != nil {
+		cells := make([]string, len(state.WinningLine))
+		for i, p := range state.WinningLine {
+			cells[i] = p.String()
+		}
+		label := "Winning line"
+		if state.Status == StatusXCompletedLine || state.Status == StatusOCompletedLine {
+			label = "Losing line"
 		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n", label, strings.Join(cells, " ")))
 	}
+	if state.EndReason != "" {
+		sb.WriteString(fmt.Sprintf("Game ended: %s\n", state.EndReason))
+	}
+	writeClockLine(sb, state)
+	sb.WriteString(fmt.Sprintf("Turn: %s | Status: %s\n", state.Turn, state.Status))
+}
 
-	// Check diagonals
-	if board[0][0] == player && board[1][1] == player && board[2][2] == player {
-		return true
+// writeGrid writes the board one row per line, with "_" for empty cells
+// When line is set, marks off the line are written in lower case so the line stands out
+func writeGrid(sb *strings.Builder, board [][]Player, line []Position) {
+	for i, row := range board {
+		for j, cell := range row {
+			sb.WriteString(cellSymbol(cell, line == nil || onLine(line, i, j)))
+			if j < len(row)-1 {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
 	}
-	if board[0][2] == player && board[1][1] == player && board[2][0] == player {
-		return true
+}
+
+// cellSymbol returns the symbol for a cell: "_" when empty, the mark in upper case when highlighted
+func cellSymbol(cell Player, highlighted bool) string {
+	switch {
+	case cell == "":
+		return "_"
+	case highlighted:
+		return string(cell)
+	default:
+		return strings.ToLower(string(cell))
 	}
+}
 
+// onLine reports whether (row, col) is one of the cells of line
+func onLine(line []Position, row, col int) bool {
+	for _, p := range line {
+		if p.Row == row && p.Col == col {
+			return true
+		}
+	}
 	return false
 }
 
-// isBoardFull checks if the board is completely filled
-func (g *TicTacToe) isBoardFull() bool {
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			if g.state.Board[i][j] == "" {
-				return false
+// lineDirections are the four directions a line can run in: right, down, down-right and down-left
+var lineDirections = [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+// hasLine reports whether player has at least k consecutive marks on the board in any direction
+func hasLine(board [][]Player, player Player, k int) bool {
+	return findLine(board, player, k) != nil
+}
+
+// findLine returns the cells of the first run of at least k consecutive marks of player, or nil
+func findLine(board [][]Player, player Player, k int) []Position {
+	for row := range board {
+		for col := range board[row] {
+			if board[row][col] != player {
+				continue
+			}
+			for _, dir := range lineDirections {
+				if length := lineLength(board, player, row, col, dir); length >= k {
+					return lineCells(row, col, dir, length)
+				}
 			}
 		}
 	}
-	return true
+	return nil
+}
+
+// lineThrough returns the cells of the run of at least k consecutive marks of player through
+// the cell (row, col), or nil if there is none
+func lineThrough(board [][]Player, row, col int, player Player, k int) []Position {
+	for _, dir := range lineDirections {
+		backwards := [2]int{-dir[0], -dir[1]}
+		// The cell itself is counted by both walks
+		behind := lineLength(board, player, row, col, backwards) - 1
+		if length := lineLength(board, player, row, col, dir) + behind; length >= k {
+			return lineCells(row-behind*dir[0], col-behind*dir[1], dir, length)
+		}
+	}
+	return nil
+}
+
+// lineCells lists length cells starting at (row, col) in direction dir
+func lineCells(row, col int, dir [2]int, length int) []Position {
+	cells := make([]Position, length)
+	for i := range cells {
+		cells[i] = Position{Row: row + i*dir[0], Col: col + i*dir[1]}
+	}
+	return cells
+}
+
+// lineLength counts consecutive marks of player starting at (row, col) and stepping in dir
+func lineLength(board [][]Player, player Player, row, col int, dir [2]int) int {
+	length := 0
+	for row >= 0 && row < len(board) && col >= 0 && col < len(board[row]) && board[row][col] == player {
+		length++
+		row += dir[0]
+		col += dir[1]
+	}
+	return length
 }
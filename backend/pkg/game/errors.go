@@ -1,6 +1,9 @@
 package game
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Error represents a game-related error
 type Error struct {
@@ -16,6 +19,30 @@ const (
 	ErrCodeWrongTurn       ErrorCode = "WRONG_TURN"
 	ErrCodeInvalidPosition ErrorCode = "INVALID_POSITION"
 	ErrCodePositionTaken   ErrorCode = "POSITION_TAKEN"
+	ErrCodeInvalidBoard    ErrorCode = "INVALID_BOARD"
+	ErrCodeColumnFull      ErrorCode = "COLUMN_FULL"
+	ErrCodeUnknownVariant  ErrorCode = "UNKNOWN_VARIANT"
+	ErrCodeWrongBoard      ErrorCode = "WRONG_BOARD"
+	ErrCodeBoardClosed     ErrorCode = "BOARD_CLOSED"
+	ErrCodeInvalidBot      ErrorCode = "INVALID_BOT"
+	ErrCodeNotStarted      ErrorCode = "GAME_NOT_STARTED"
+	ErrCodeNoAnalysis      ErrorCode = "ANALYSIS_UNSUPPORTED"
+	ErrCodeInvalidUndo     ErrorCode = "INVALID_UNDO"
+	ErrCodeInvalidOffer    ErrorCode = "INVALID_DRAW_OFFER"
+	ErrCodeInProgress      ErrorCode = "GAME_IN_PROGRESS"
+	ErrCodeSeriesOver      ErrorCode = "SERIES_OVER"
+	ErrCodeInvalidSeries   ErrorCode = "INVALID_SERIES"
+	ErrCodeInvalidClock    ErrorCode = "INVALID_TIME_CONTROL"
+	ErrCodeInvalidNotation ErrorCode = "INVALID_NOTATION"
+	ErrCodeInvalidReplay   ErrorCode = "INVALID_REPLAY"
+	ErrCodeInvalidFirst    ErrorCode = "INVALID_FIRST_MOVE"
+	ErrCodeNotHost         ErrorCode = "NOT_HOST"
+	ErrCodeQuantumMove     ErrorCode = "INVALID_QUANTUM_MOVE"
+	ErrCodeCollapse        ErrorCode = "COLLAPSE_PENDING"
+	ErrCodeInvalidCollapse ErrorCode = "INVALID_COLLAPSE"
+	ErrCodeInvalidRules    ErrorCode = "INVALID_RULES"
+	ErrCodeInvalidPlayers  ErrorCode = "INVALID_PLAYERS"
+	ErrCodeInvalidVote     ErrorCode = "INVALID_VOTE"
 )
 
 // Predefined errors
@@ -30,12 +57,36 @@ var (
 	}
 	ErrInvalidPosition = &Error{
 		Code:    ErrCodeInvalidPosition,
-		Message: "invalid position (must be 0-2)",
+		Message: "invalid position (outside the board)",
 	}
 	ErrPositionTaken = &Error{
 		Code:    ErrCodePositionTaken,
 		Message: "position already taken",
 	}
+	ErrNothingToUndo = &Error{
+		Code:    ErrCodeInvalidUndo,
+		Message: "no move of yours to take back",
+	}
+	ErrNoUndoRequest = &Error{
+		Code:    ErrCodeInvalidUndo,
+		Message: "no takeback request to accept",
+	}
+	ErrOwnUndoRequest = &Error{
+		Code:    ErrCodeInvalidUndo,
+		Message: "cannot accept your own takeback request",
+	}
+	ErrNoDrawOffer = &Error{
+		Code:    ErrCodeInvalidOffer,
+		Message: "no draw offer to accept",
+	}
+	ErrOwnDrawOffer = &Error{
+		Code:    ErrCodeInvalidOffer,
+		Message: "cannot accept your own draw offer",
+	}
+	ErrNoCollapse = &Error{
+		Code:    ErrCodeInvalidCollapse,
+		Message: "no collapse to choose",
+	}
 )
 
 // Error implements the error interface
@@ -59,10 +110,351 @@ func NewWrongTurnError(player, currentTurn Player) *Error {
 	}
 }
 
-// NewInvalidPositionError creates a new invalid position error
-func NewInvalidPositionError(row, col int) *Error {
+// NewInvalidPositionError creates a new invalid position error for a rows x cols board
+func NewInvalidPositionError(row, col, rows, cols int) *Error {
 	return &Error{
 		Code:    ErrCodeInvalidPosition,
-		Message: fmt.Sprintf("invalid position: row=%d, col=%d (must be 0-2)", row, col),
+		Message: fmt.Sprintf("invalid position: row=%d, col=%d (row must be 0-%d, col must be 0-%d)", row, col, rows-1, cols-1),
+	}
+}
+
+// NewInvalidBoardError creates a new invalid board configuration error
+func NewInvalidBoardError(config BoardConfig, reason string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidBoard,
+		Message: fmt.Sprintf("invalid board %s: %s", config, reason),
+	}
+}
+
+// NewInvalidColumnError creates a new invalid column error for a board with cols columns
+func NewInvalidColumnError(col, cols int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPosition,
+		Message: fmt.Sprintf("invalid column: %d (must be 0-%d)", col, cols-1),
+	}
+}
+
+// NewColumnFullError creates a new column full error
+func NewColumnFullError(col int) *Error {
+	return &Error{
+		Code:    ErrCodeColumnFull,
+		Message: fmt.Sprintf("column %d is full", col),
+	}
+}
+
+// NewFloatingPieceError creates a new error for a piece placed above the lowest free row
+func NewFloatingPieceError(row, col, landing int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPosition,
+		Message: fmt.Sprintf("invalid position: row=%d, col=%d (pieces drop to row %d in this column)", row, col, landing),
+	}
+}
+
+// NewUnknownVariantError creates a new unknown game variant error
+func NewUnknownVariantError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeUnknownVariant,
+		Message: fmt.Sprintf("unknown game variant: %s", variant),
+	}
+}
+
+// NewInvalidSubBoardMoveError creates a new invalid board/cell error for a game with boards local boards
+func NewInvalidSubBoardMoveError(board, cell, boards int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPosition,
+		Message: fmt.Sprintf("invalid position: board=%d, cell=%d (board must be 0-%d, cell must be 0-8)", board, cell, boards-1),
+	}
+}
+
+// NewWrongBoardError creates a new error for a move outside the board the player is sent to
+func NewWrongBoardError(board, forcedBoard int) *Error {
+	return &Error{
+		Code:    ErrCodeWrongBoard,
+		Message: fmt.Sprintf("must play in board %d (tried board %d)", forcedBoard, board),
+	}
+}
+
+// NewBoardClosedError creates a new error for a move on a local board that is already decided
+func NewBoardClosedError(board int, status Status) *Error {
+	return &Error{
+		Code:    ErrCodeBoardClosed,
+		Message: fmt.Sprintf("board %d is already decided: %s", board, status),
+	}
+}
+
+// NewBoardDeadError creates a new error for a move on a Notakto board that already has three in a row
+func NewBoardDeadError(board int) *Error {
+	return &Error{
+		Code:    ErrCodeBoardClosed,
+		Message: fmt.Sprintf("board %d is dead (it already has three in a row)", board),
+	}
+}
+
+// NewInvalidBoardCountError creates a new error for a Notakto game with too few or too many boards
+func NewInvalidBoardCountError(boards int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidBoard,
+		Message: fmt.Sprintf("invalid number of boards: %d (must be 1-%d)", boards, MaxNotaktoBoards),
+	}
+}
+
+// NewInvalidDifficultyError creates a new unknown bot difficulty error
+func NewInvalidDifficultyError(level string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidBot,
+		Message: fmt.Sprintf("unknown bot difficulty: %s (must be easy, medium or hard)", level),
+	}
+}
+
+// NewBotUnsupportedError creates a new error for a variant the bot cannot play
+func NewBotUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidBot,
+		Message: fmt.Sprintf("bots cannot play %s", variant),
+	}
+}
+
+// NewGameNotStartedError creates a new error for an action that needs the game to have started
+func NewGameNotStartedError() *Error {
+	return &Error{
+		Code:    ErrCodeNotStarted,
+		Message: "game has not started yet",
+	}
+}
+
+// NewUndoPendingError creates a new error for a takeback requested while another is pending
+func NewUndoPendingError(requester Player) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidUndo,
+		Message: fmt.Sprintf("a takeback requested by %s is already pending", requester),
+	}
+}
+
+// NewAnalysisUnsupportedError creates a new error for hints or analysis on a variant the solver cannot search
+func NewAnalysisUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeNoAnalysis,
+		Message: fmt.Sprintf("hints and analysis are not supported for variant %s", variant),
+	}
+}
+
+// NewDrawOfferPendingError creates a new error for a draw offered while another offer is pending
+func NewDrawOfferPendingError(offeredBy Player) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidOffer,
+		Message: fmt.Sprintf("a draw offered by %s is already pending", offeredBy),
+	}
+}
+
+// NewGameInProgressError creates a new error for an action that needs the game to be over
+func NewGameInProgressError() *Error {
+	return &Error{
+		Code:    ErrCodeInProgress,
+		Message: "game is still in progress (finish, resign or reset it first)",
+	}
+}
+
+// NewSeriesOverError creates a new error for a rematch after the series has ended
+func NewSeriesOverError(winner Player) *Error {
+	message := "series is over (tied)"
+	if winner != "" {
+		message = fmt.Sprintf("series is over: %s won", winner)
+	}
+	return &Error{
+		Code:    ErrCodeSeriesOver,
+		Message: message,
+	}
+}
+
+// NewInvalidSeriesError creates a new error for a series length that is not a positive number of games
+func NewInvalidSeriesError(bestOf int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidSeries,
+		Message: fmt.Sprintf("invalid series length %d (must be at least 1 game)", bestOf),
+	}
+}
+
+// NewInvalidTimeControlError creates a new error for a negative clock budget
+func NewInvalidTimeControlError(budget time.Duration) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidClock,
+		Message: fmt.Sprintf("invalid time control %s (must be positive)", budget),
+	}
+}
+
+// NewTimeControlUnsupportedError creates a new error for clocks on a variant that does not support them
+func NewTimeControlUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidClock,
+		Message: fmt.Sprintf("time controls are not supported for variant %s", variant),
+	}
+}
+
+// NewInvalidNotationError creates a new error for a position or move list that cannot be read
+func NewInvalidNotationError(notation, reason string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidNotation,
+		Message: fmt.Sprintf("invalid notation %q: %s", notation, reason),
+	}
+}
+
+// NewDecidedPositionError creates a new error for a start position that is already won or drawn
+func NewDecidedPositionError() *Error {
+	return &Error{
+		Code:    ErrCodeInvalidNotation,
+		Message: "position is already decided (start from a position with moves left to play)",
+	}
+}
+
+// NewNotationUnsupportedError creates a new error for a start position on a variant that cannot load it
+func NewNotationUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidNotation,
+		Message: fmt.Sprintf("this start position is not supported for variant %s", variant),
+	}
+}
+
+// NewInvalidReplayError creates a new error for a replay of a move the game does not have
+func NewInvalidReplayError(number, total int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidReplay,
+		Message: fmt.Sprintf("no move %d to replay (the game has %d moves, use 0-%d)", number, total, total),
+	}
+}
+
+// NewReplayUnsupportedError creates a new error for a replay on a variant that cannot rebuild its positions
+func NewReplayUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidReplay,
+		Message: fmt.Sprintf("replays are not supported for variant %s", variant),
+	}
+}
+
+// NewInvalidFirstMoveError creates a new error for an unknown first-move rule
+func NewInvalidFirstMoveError(rule string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidFirst,
+		Message: fmt.Sprintf("invalid first move %q (expected x, o, random, alternate or loser)", rule),
+	}
+}
+
+// NewFirstMoveUnsupportedError creates a new error for a first-move rule on a variant where X must move first
+func NewFirstMoveUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidFirst,
+		Message: fmt.Sprintf("choosing who moves first is not supported for variant %s", variant),
+	}
+}
+
+// NewNotHostError creates a new error for a settings change by a player other than the host
+func NewNotHostError() *Error {
+	return &Error{
+		Code:    ErrCodeNotHost,
+		Message: "only the host (the first player to join) can change this setting",
+	}
+}
+
+// NewQuantumMoveError creates a new error for a quantum tic-tac-toe move of the wrong shape
+func NewQuantumMoveError(reason string) *Error {
+	return &Error{
+		Code:    ErrCodeQuantumMove,
+		Message: reason,
+	}
+}
+
+// NewCollapsePendingError creates a new error for a move made before a pending collapse is settled
+func NewCollapsePendingError(pending *PendingCollapse) *Error {
+	return &Error{
+		Code:    ErrCodeCollapse,
+		Message: fmt.Sprintf("a cycle must collapse first: %s chooses %s or %s", pending.Chooser, pending.Cells[0], pending.Cells[1]),
+	}
+}
+
+// NewInvalidCollapseError creates a new error for a collapse into a cell the cycle-closing mark is not in
+func NewInvalidCollapseError(cell Position, pending *PendingCollapse) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidCollapse,
+		Message: fmt.Sprintf("cannot collapse into %s (choose %s or %s)", cell, pending.Cells[0], pending.Cells[1]),
+	}
+}
+
+// NewMisereUnsupportedError creates a new error for misère rules on a variant that cannot play them
+func NewMisereUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidRules,
+		Message: fmt.Sprintf("misère rules are not supported for variant %s", variant),
+	}
+}
+
+// NewInvalidPlayerCountError creates a new error for a seat count outside MinPlayers-MaxPlayers
+func NewInvalidPlayerCountError(players int) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPlayers,
+		Message: fmt.Sprintf("invalid number of players %d (must be %d-%d)", players, MinPlayers, MaxPlayers),
+	}
+}
+
+// NewPlayerCountUnsupportedError creates a new error for more than two players on a variant that cannot seat them
+func NewPlayerCountUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPlayers,
+		Message: fmt.Sprintf("more than two players are not supported for variant %s", variant),
+	}
+}
+
+// NewInvalidSymbolsError creates a new error for unusable seat symbols
+func NewInvalidSymbolsError(reason string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPlayers,
+		Message: fmt.Sprintf("invalid player symbols: %s", reason),
+	}
+}
+
+// NewMultiplayerUnsupportedError creates a new error for a feature that only works with two players
+func NewMultiplayerUnsupportedError(feature string) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidPlayers,
+		Message: fmt.Sprintf("%s cannot be used in games with more than two players", feature),
+	}
+}
+
+// NewNotCrowdSessionError creates a new error for a vote in a session whose players make their own moves
+func NewNotCrowdSessionError() *Error {
+	return &Error{
+		Code:    ErrCodeInvalidVote,
+		Message: "this is not a crowd-play session (create one with new-crowd)",
+	}
+}
+
+// NewWrongTeamError creates a new error for a vote for the team that is not to move
+func NewWrongTeamError(team, turn Player) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidVote,
+		Message: fmt.Sprintf("team %s cannot vote now (voting is open for team %s)", team, turn),
+	}
+}
+
+// NewIllegalVoteError creates a new error for a vote for a free cell the team may not play, e.g. above
+// the landing cell of a Connect Four column
+func NewIllegalVoteError(cell Position) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidVote,
+		Message: fmt.Sprintf("%s is not a legal move right now", cell),
+	}
+}
+
+// NewCrowdUnsupportedError creates a new error for crowd play on a variant whose moves cannot be voted on cell by cell
+func NewCrowdUnsupportedError(variant Variant) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidVote,
+		Message: fmt.Sprintf("crowd play is not supported for variant %s", variant),
+	}
+}
+
+// NewInvalidVotingWindowError creates a new error for a negative voting window
+func NewInvalidVotingWindowError(window time.Duration) *Error {
+	return &Error{
+		Code:    ErrCodeInvalidVote,
+		Message: fmt.Sprintf("invalid voting window %s (must be positive)", window),
 	}
 }
@@ -0,0 +1,516 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record types the event log writes besides the session events it records
+const (
+	// RecordSessionCreated is written when a session is added to the store
+	RecordSessionCreated EventType = "session_created"
+	// RecordSessionDeleted is written when a session is deleted or expires
+	RecordSessionDeleted EventType = "session_deleted"
+)
+
+// EventRecorder is implemented by stores that record the events of their sessions as they happen
+// Sessions call it as each event happens, under the lock the change was made with (see Session.publish),
+// so it must not call back into the session
+type EventRecorder interface {
+	// Record records an event, with the details needed to replay it, and returns the number of its record,
+	// 0 if it was not recorded
+	Record(record *LogRecord) uint64
+}
+
+// LogRecord is one line of the event log: what happened, with what it takes to do it again on replay,
+// but without the players' tokens
+type LogRecord struct {
+	// Seq numbers the records, counting on across segments
+	Seq uint64 `json:"seq"`
+	Event
+	// Session is the session as it was created, with its tokens hashed, for session_created records
+	Session *SessionSnapshot `json:"session,omitempty"`
+	// TokenHash is the hash of the token of the player who joined, for player_joined records, see hashToken
+	TokenHash PlayerToken `json:"token_hash,omitempty"`
+	// Bot is the difficulty of a bot that joined, for player_joined records
+	Bot Difficulty `json:"bot,omitempty"`
+	// FirstMove is the rule the host chose, for first_move records
+	FirstMove FirstMove `json:"first_move,omitempty"`
+}
+
+// LogStore saves sessions like FileStore and also writes every change to them to an append-only event log
+// as it happens: sessions being created and deleted, players joining (under a hash of their token), and every
+// event (moves, takebacks, resets, rematches, first-move choices, ...). On startup each session is rebuilt from its
+// file, or from its creation record if it was never saved, and the records logged after that are replayed on it,
+// so a crash loses nothing the log holds. Pending takeback requests and draw offers are not logged.
+// The log is split into segments of a given number of records. Once every session is saved (see Manager.SaveSessions),
+// the full segments holding no record a saved session is missing are deleted, compacting the log into the session files.
+type LogStore struct {
+	*FileStore
+	log         *os.File
+	seq         uint64          // the number of the last record written
+	records     int             // the records in the current segment
+	rotateAfter int             // start a new segment once the current one holds this many records, 0 to never
+	restored    map[string]bool // sessions loaded from the store, which are put back without a session_created record
+	loaded      []*SessionSnapshot
+	loadErr     error
+	mu          sync.Mutex // serializes writes to the log
+}
+
+// logSegment is a file of the event log
+type logSegment struct {
+	path  string
+	first uint64 // the number of the segment's first record
+}
+
+// NewLogStore opens the session files and the event log in dir, creating the directory if needed,
+// and reads back the sessions they hold. A truncated final record, left by a crash in the middle of a write, is dropped.
+func NewLogStore(dir string, rotateAfter int) (*LogStore, error) {
+	files, err := NewFileStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	l := &LogStore{
+		FileStore:   files,
+		rotateAfter: rotateAfter,
+		restored:    make(map[string]bool),
+	}
+	if err := l.replay(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// segmentPath returns the path of the log segment starting with record seq
+func (l *LogStore) segmentPath(seq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("events-%020d.log", seq))
+}
+
+// segments lists the segments of the event log, oldest first
+func (l *LogStore) segments() ([]logSegment, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event log directory: %w", err)
+	}
+	var segments []logSegment
+	for _, entry := range entries {
+		var first uint64
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+		if _, err := fmt.Sscanf(entry.Name(), "events-%d.log", &first); err != nil {
+			continue
+		}
+		segments = append(segments, logSegment{path: filepath.Join(l.dir, entry.Name()), first: first})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].first < segments[j].first
+	})
+	return segments, nil
+}
+
+// replay loads the saved sessions, and the sessions created since that were never saved, each with the records
+// logged after it for RestoreSessions to replay, keeping them for Load; then it opens the last segment of the log
+// for writing
+// Records that cannot be read are skipped and reported by Load, except for a final record cut short,
+// which is cut off the log so new records start on a line of their own
+func (l *LogStore) replay() error {
+	snapshots, loadErr := l.FileStore.Load()
+	errs := []error{loadErr}
+
+	segments, err := l.segments()
+	if err != nil {
+		return err
+	}
+	sessions := make(map[string]*SessionSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		sessions[snapshot.ID] = snapshot
+		l.seq = max(l.seq, snapshot.LogSeq)
+	}
+
+	// The segments left after the last compaction hold every record a session may be missing
+	deleted := make(map[string]bool)
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		records, err := readSegment(segment.path, last)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, record := range records {
+			l.seq = max(l.seq, record.Seq)
+			snapshot, known := sessions[record.SessionID]
+			switch {
+			case record.Type == RecordSessionCreated:
+				// A session created but never saved comes back as it was created
+				if !known && record.Session != nil {
+					snapshot = record.Session
+					snapshot.LogSeq = record.Seq
+					sessions[snapshot.ID] = snapshot
+					snapshots = append(snapshots, snapshot)
+				}
+			case record.Type == RecordSessionDeleted:
+				deleted[record.SessionID] = true
+			case known && record.Seq > snapshot.LogSeq:
+				snapshot.pending = append(snapshot.pending, record)
+			}
+		}
+		if last {
+			l.seq = max(l.seq, segment.first-1)
+			l.records = len(records)
+		}
+	}
+
+	for _, snapshot := range snapshots {
+		if deleted[snapshot.ID] {
+			errs = append(errs, l.FileStore.Delete(snapshot.ID))
+			continue
+		}
+		l.loaded = append(l.loaded, snapshot)
+		l.restored[snapshot.ID] = true
+	}
+	l.loadErr = errors.Join(errs...)
+
+	path := l.segmentPath(l.seq + 1)
+	if len(segments) > 0 {
+		path = segments[len(segments)-1].path
+	}
+	log, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	l.log = log
+	return nil
+}
+
+// readSegment reads the records of a log segment
+// In the last segment, a final line without its newline is a record a crash cut short, and is cut off the file
+func readSegment(path string, last bool) ([]LogRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	var records []LogRecord
+	var errs []error
+	for offset, line := 0, 1; offset < len(data); line++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			if !last {
+				errs = append(errs, fmt.Errorf("%s line %d: record cut short", filepath.Base(path), line))
+				break
+			}
+			if err := os.Truncate(path, int64(offset)); err != nil {
+				return nil, fmt.Errorf("failed to drop truncated event log record: %w", err)
+			}
+			break
+		}
+
+		record := LogRecord{}
+		if err := json.Unmarshal(data[offset:offset+end], &record); err != nil {
+			errs = append(errs, fmt.Errorf("%s line %d: %w", filepath.Base(path), line, err))
+		} else {
+			records = append(records, record)
+		}
+		offset += end + 1
+	}
+	return records, errors.Join(errs...)
+}
+
+// Load returns the sessions read back when the store was opened, each with the records logged after it,
+// which RestoreSessions replays on it
+func (l *LogStore) Load() ([]*SessionSnapshot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loaded, l.loadErr
+}
+
+// replayLog brings a session restored from a snapshot up to date by doing again what the records logged after
+// the snapshot was taken record. Moves are played by the game's rules and keep their timestamps, but the clocks
+// are left as saved. Records that cannot be replayed are skipped and reported.
+// Must be called before the session is shared
+func (s *Session) replayLog(records []LogRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	// What is done again was logged and reported to the hooks the first time
+	recorder, events := s.recorder, s.events
+	s.recorder, s.events = nil, nil
+	defer func() { s.recorder, s.events = recorder, events }()
+
+	// Moves, collapses, takebacks and results are gathered and played on the game at once,
+	// before the next record that needs the game up to date
+	moves, status, changed := s.Game.GetState().Moves, Status(""), false
+	flush := func() error {
+		if !changed {
+			return nil
+		}
+		err := s.catchUp(moves, status)
+		moves, status, changed = s.Game.GetState().Moves, "", false
+		return err
+	}
+
+	// Replaying touches the session, which was last active when its last record was logged
+	lastActive := s.lastActive.Load()
+	var errs []error
+	var previous EventType
+	for _, record := range records {
+		var err error
+		switch record.Type {
+		case EventMoveMade:
+			if record.Move == nil || record.Move.Number != len(moves)+1 {
+				err = fmt.Errorf("move does not follow on from move %d", len(moves))
+				break
+			}
+			moves, changed = append(moves, *record.Move), true
+		case EventCollapse:
+			if record.Move == nil || record.Move.Number < 1 || record.Move.Number > len(moves) {
+				err = fmt.Errorf("collapse of a move that was not played")
+				break
+			}
+			moves[record.Move.Number-1].CollapsedTo, changed = record.Move.CollapsedTo, true
+		case EventTakeback:
+			if record.Undone < 1 || record.Undone > len(moves) {
+				err = fmt.Errorf("takeback of %d moves out of %d", record.Undone, len(moves))
+				break
+			}
+			// The game goes on, even if it had ended off the board
+			moves, status, changed = moves[:len(moves)-record.Undone], "", true
+		case EventGameOver:
+			status, changed = record.Status, true
+		default:
+			err = flush()
+			if err == nil {
+				err = s.replayRecord(record, previous)
+			}
+			// Resets and rematches start a new game
+			moves = s.Game.GetState().Moves
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record %d (%s): %w", record.Seq, record.Type, err))
+		}
+		previous = record.Type
+		s.logSeq.Store(record.Seq)
+		lastActive = max(lastActive, record.Time.UnixNano())
+	}
+	errs = append(errs, flush())
+	s.lastActive.Store(lastActive)
+	return errors.Join(errs...)
+}
+
+// replayRecord does again what a record other than a move or a result records; previous is the type of the
+// session's record before it
+// Must be called before the session is shared, with the game up to date with the records before it
+func (s *Session) replayRecord(record LogRecord, previous EventType) error {
+	switch record.Type {
+	case EventPlayerJoined:
+		if record.TokenHash == "" {
+			return fmt.Errorf("no token hash")
+		}
+		s.Players[record.TokenHash] = record.Player
+		if record.Bot != "" {
+			s.bot = NewBot(record.Player, record.Bot)
+		} else if s.host == "" {
+			s.host = record.TokenHash
+		}
+	case EventGameStarted:
+		// A coin toss may have let the other player start
+		if mover, ok := s.Game.(FirstMover); ok && record.Player != "" && s.Game.GetState().Turn != record.Player {
+			mover.SetFirstPlayer(record.Player)
+		}
+		s.Game.StartGame()
+	case EventFirstMove:
+		s.setFirstMove(record.FirstMove, record.Player)
+	case EventRematch:
+		return s.Rematch()
+	case EventReset:
+		// A rematch resets the board itself, so its reset was replayed with it
+		if previous == EventRematch {
+			return nil
+		}
+		return s.Reset()
+	default:
+		return fmt.Errorf("cannot replay %s records", record.Type)
+	}
+	return nil
+}
+
+// catchUp replays moves on the game's initial state, keeping the clocks as they are,
+// and ends the game with status if it is still in play
+// Must be called before the session is shared
+func (s *Session) catchUp(moves []Move, status Status) error {
+	g, ok := s.Game.(restorable)
+	r, rewinds := s.Game.(rewindable)
+	if !ok || !rewinds {
+		return NewReplayUnsupportedError(s.Game.GetState().Variant)
+	}
+
+	// The engine is not shared yet, so its state can be rebuilt without its lock
+	saved, firstPlayer := g.saveState()
+	if err := r.rebuild(r, moves); err != nil {
+		return err
+	}
+	state, _ := g.saveState()
+	state.Clock = saved.Clock
+	if status != "" && state.Status == StatusPlaying {
+		state.Status = status
+	}
+	g.restoreState(state, firstPlayer)
+	return nil
+}
+
+// Put adds a new session, logging its creation, or saves the current state of a stored one like FileStore
+func (l *LogStore) Put(session *Session) error {
+	if _, ok := l.Get(session.ID); ok {
+		return l.FileStore.Put(session)
+	}
+	l.mu.Lock()
+	restored := l.restored[session.ID]
+	delete(l.restored, session.ID)
+	l.mu.Unlock()
+	if restored {
+		return l.FileStore.Put(session)
+	}
+
+	if err := l.logCreated(session); err != nil {
+		return err
+	}
+	if err := l.FileStore.Put(session); err != nil {
+		// The session was not added, so it must not come back from its creation record either
+		l.mu.Lock()
+		_, deleteErr := l.append(&LogRecord{Event: Event{Type: RecordSessionDeleted, SessionID: session.ID, Time: time.Now()}})
+		l.mu.Unlock()
+		return errors.Join(err, deleteErr)
+	}
+	return nil
+}
+
+// logCreated logs the creation of a new session, with everything needed to bring it back without its file
+func (l *LogStore) logCreated(session *Session) error {
+	created := session.Snapshot()
+	created.hashTokens()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seq, err := l.append(&LogRecord{
+		Event:   Event{Type: RecordSessionCreated, SessionID: session.ID, Time: time.Now()},
+		Session: created,
+	})
+	if err != nil {
+		return err
+	}
+	// The session is saved as of its creation record, so replays start after it
+	session.logSeq.Store(seq)
+	return l.rotateIfDue()
+}
+
+// Delete removes the session with the given ID and its file, and logs its deletion
+func (l *LogStore) Delete(id string) error {
+	if _, ok := l.Get(id); !ok {
+		return nil
+	}
+	l.mu.Lock()
+	_, err := l.append(&LogRecord{Event: Event{Type: RecordSessionDeleted, SessionID: id, Time: time.Now()}})
+	if err == nil {
+		err = l.rotateIfDue()
+	}
+	l.mu.Unlock()
+	return errors.Join(err, l.FileStore.Delete(id))
+}
+
+// Record logs a session event as it happens
+// Events of sessions that are not in the store (yet, or any more) are skipped. A record that cannot be written
+// is missing from the history, but the state it led to is still kept by the session's next save.
+func (l *LogStore) Record(record *LogRecord) uint64 {
+	if _, ok := l.Get(record.SessionID); !ok {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seq, err := l.append(record)
+	if err != nil {
+		return 0
+	}
+	l.rotateIfDue()
+	return seq
+}
+
+// append writes a record to the end of the log, flushes it to disk and returns its number
+// Must be called with the lock held
+func (l *LogStore) append(record *LogRecord) (uint64, error) {
+	record.Seq = l.seq + 1
+	data, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode %s record: %w", record.Type, err)
+	}
+	if _, err := l.log.Write(append(data, '\n')); err != nil {
+		return 0, fmt.Errorf("failed to write %s record: %w", record.Type, err)
+	}
+	if err := l.log.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to write %s record: %w", record.Type, err)
+	}
+	l.seq++
+	l.records++
+	return l.seq, nil
+}
+
+// rotateIfDue starts a new segment once the current one holds enough records, leaving the full one to compact
+// If the new segment cannot be created, records go on into the current one and rotating is tried again later
+// Must be called with the lock held
+func (l *LogStore) rotateIfDue() error {
+	if l.rotateAfter <= 0 || l.records < l.rotateAfter {
+		return nil
+	}
+	log, err := os.OpenFile(l.segmentPath(l.seq+1), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to start a new event log segment: %w", err)
+	}
+	l.log.Close()
+	l.log = log
+	l.records = 0
+	return nil
+}
+
+// compactor is implemented by stores that can drop what they no longer need once every session is saved
+type compactor interface {
+	// compact is called by Manager.SaveSessions right after it saved every session
+	compact() error
+}
+
+// compact saves every session as of the last record written, then deletes the full segments whose records all have
+// a number no higher than that, as no saved session needs them replayed
+func (l *LogStore) compact() error {
+	segments, err := l.segments()
+	if err != nil || len(segments) < 2 {
+		return err
+	}
+	l.mu.Lock()
+	seq := l.seq
+	l.mu.Unlock()
+
+	// A session's records are written under the locks its snapshot waits for, so a snapshot taken now includes
+	// every record up to seq, and a session idle since long before is saved as of seq too
+	for _, session := range l.List() {
+		snapshot := session.Snapshot()
+		snapshot.LogSeq = max(snapshot.LogSeq, seq)
+		if err := l.FileStore.save(session, snapshot); err != nil {
+			return fmt.Errorf("failed to compact event log: %w", err)
+		}
+	}
+
+	// A segment's last record is the one before the next segment's first; the last segment is still being written
+	var errs []error
+	for i := 0; i+1 < len(segments) && segments[i+1].first-1 <= seq; i++ {
+		if err := os.Remove(segments[i].path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to compact event log: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,391 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openLogManager opens the log store in dir and a manager with the sessions it restores
+func openLogManager(t *testing.T, dir string, rotateAfter int) (*Manager, *LogStore) {
+	t.Helper()
+	store, err := NewLogStore(dir, rotateAfter)
+	if err != nil {
+		t.Fatalf("NewLogStore: %v", err)
+	}
+	t.Cleanup(func() { store.log.Close() })
+	m := NewManager(WithStore(store))
+	if _, err := m.RestoreSessions(); err != nil {
+		t.Fatalf("RestoreSessions: %v", err)
+	}
+	return m, store
+}
+
+// startLoggedGame creates a session with both players joined and saves it, so only what follows is left to the log
+func startLoggedGame(t *testing.T, m *Manager, opts ...SessionOption) (*Session, PlayerToken, PlayerToken) {
+	t.Helper()
+	id, err := m.CreateSession(opts...)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	session, _ := m.GetSession(id)
+	x, _, err := session.JoinSession()
+	if err != nil {
+		t.Fatalf("JoinSession: %v", err)
+	}
+	o, _, err := session.JoinSession()
+	if err != nil {
+		t.Fatalf("JoinSession: %v", err)
+	}
+	if err := m.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	return session, x, o
+}
+
+// playMoves plays moves alternately for X and O, starting with X
+func playMoves(t *testing.T, session *Session, moves ...Position) {
+	t.Helper()
+	for i, move := range moves {
+		player := PlayerX
+		if i%2 == 1 {
+			player = PlayerO
+		}
+		if err := session.Game.MakeMove(move.Row, move.Col, player); err != nil {
+			t.Fatalf("move %d: %v", i+1, err)
+		}
+	}
+}
+
+func TestLogStoreReplaysUnsavedMoves(t *testing.T) {
+	dir := t.TempDir()
+	m, _ := openLogManager(t, dir, 0)
+	session, x, o := startLoggedGame(t, m)
+	// The moves are only logged: the session is not saved again before the "crash"
+	playMoves(t, session, Position{1, 1}, Position{0, 0}, Position{0, 2})
+
+	restored, _ := openLogManager(t, dir, 0)
+	got, err := restored.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("session not restored: %v", err)
+	}
+	state := got.Game.GetState()
+	if len(state.Moves) != 3 {
+		t.Fatalf("restored %d moves, want 3", len(state.Moves))
+	}
+	if state.Board[1][1] != PlayerX || state.Board[0][0] != PlayerO || state.Board[0][2] != PlayerX {
+		t.Errorf("restored board %v does not match the moves played", state.Board)
+	}
+	if state.Turn != PlayerO {
+		t.Errorf("turn = %s, want O", state.Turn)
+	}
+	for token, want := range map[PlayerToken]Player{x: PlayerX, o: PlayerO} {
+		if player, err := got.GetPlayer(token); err != nil || player != want {
+			t.Errorf("token of %s resolves to %q, %v", want, player, err)
+		}
+	}
+	// The restored game goes on from the replayed position
+	if err := got.Game.MakeMove(2, 0, PlayerO); err != nil {
+		t.Errorf("move after replay: %v", err)
+	}
+}
+
+func TestLogStoreReplaysGameOver(t *testing.T) {
+	dir := t.TempDir()
+	m, _ := openLogManager(t, dir, 0)
+	session, _, _ := startLoggedGame(t, m)
+	playMoves(t, session, Position{0, 0}, Position{1, 0}, Position{0, 1}, Position{1, 1}, Position{0, 2})
+
+	restored, _ := openLogManager(t, dir, 0)
+	got, err := restored.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("session not restored: %v", err)
+	}
+	if status := got.Game.GetState().Status; status != StatusXWins {
+		t.Errorf("status = %s, want %s", status, StatusXWins)
+	}
+}
+
+func TestLogStoreDropsTruncatedRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{"complete record without newline", `{"seq":99,"type":"move_made","session_id":"x"}`},
+		{"record cut short", `{"seq":99,"type":"mo`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			m, store := openLogManager(t, dir, 0)
+			session, _, _ := startLoggedGame(t, m)
+			playMoves(t, session, Position{1, 1})
+
+			path := store.log.Name()
+			appendFile(t, path, tt.tail)
+
+			restored, store := openLogManager(t, dir, 0)
+			if _, err := store.Load(); err != nil {
+				t.Errorf("Load: %v", err)
+			}
+			got, err := restored.GetSession(session.ID)
+			if err != nil {
+				t.Fatalf("session not restored: %v", err)
+			}
+			if err := got.Game.MakeMove(0, 0, PlayerO); err != nil {
+				t.Fatalf("move after replay: %v", err)
+			}
+
+			// The next record starts on a line of its own, so the log reads back cleanly
+			records, err := readSegment(path, true)
+			if err != nil {
+				t.Fatalf("readSegment: %v", err)
+			}
+			lastRecord := records[len(records)-1]
+			if lastRecord.Type != EventMoveMade || lastRecord.Move == nil || lastRecord.Move.Number != 2 {
+				t.Errorf("last record = %+v, want the second move", lastRecord)
+			}
+			for i := 1; i < len(records); i++ {
+				if records[i].Seq != records[i-1].Seq+1 {
+					t.Errorf("record %d has seq %d after %d", i, records[i].Seq, records[i-1].Seq)
+				}
+			}
+		})
+	}
+}
+
+func TestLogStoreForgetsDeletedSessions(t *testing.T) {
+	dir := t.TempDir()
+	m, _ := openLogManager(t, dir, 0)
+	kept, _, _ := startLoggedGame(t, m)
+	deleted, _, _ := startLoggedGame(t, m)
+	if err := m.DeleteSession(deleted.ID); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+
+	restored, _ := openLogManager(t, dir, 0)
+	if _, err := restored.GetSession(kept.ID); err != nil {
+		t.Errorf("kept session not restored: %v", err)
+	}
+	if _, err := restored.GetSession(deleted.ID); err == nil {
+		t.Errorf("deleted session %s was restored", deleted.ID)
+	}
+}
+
+func TestLogStoreRotatesSegments(t *testing.T) {
+	dir := t.TempDir()
+	m, store := openLogManager(t, dir, 2)
+	session, _, _ := startLoggedGame(t, m)
+	playMoves(t, session, Position{1, 1}, Position{0, 0}, Position{2, 2}, Position{0, 2})
+
+	segments, err := store.segments()
+	if err != nil {
+		t.Fatalf("segments: %v", err)
+	}
+	if len(segments) < 3 {
+		t.Fatalf("log has %d segments, want it rotated", len(segments))
+	}
+	var seq uint64
+	for _, segment := range segments {
+		if segment.first != seq+1 {
+			t.Errorf("segment %s starts at %d, want %d", filepath.Base(segment.path), segment.first, seq+1)
+		}
+		records, err := readSegment(segment.path, false)
+		if err != nil {
+			t.Fatalf("readSegment: %v", err)
+		}
+		for _, record := range records {
+			seq = record.Seq
+		}
+	}
+
+	// Replay reads on across segments
+	restored, _ := openLogManager(t, dir, 2)
+	got, err := restored.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("session not restored: %v", err)
+	}
+	if moves := len(got.Game.GetState().Moves); moves != 4 {
+		t.Errorf("restored %d moves, want 4", moves)
+	}
+}
+
+func TestLogStoreCompactsSegments(t *testing.T) {
+	dir := t.TempDir()
+	m, store := openLogManager(t, dir, 2)
+	// The idle session's last record is in the oldest segment, which must not keep it from being compacted
+	idle, _, _ := startLoggedGame(t, m)
+	session, _, _ := startLoggedGame(t, m)
+	playMoves(t, session, Position{1, 1}, Position{0, 0}, Position{2, 2}, Position{0, 2})
+	if err := m.SaveSessions(); err != nil {
+		t.Fatalf("SaveSessions: %v", err)
+	}
+
+	segments, err := store.segments()
+	if err != nil {
+		t.Fatalf("segments: %v", err)
+	}
+	if len(segments) != 1 || segments[0].first == 1 {
+		t.Fatalf("log has %d segments from record %d after compaction, want only the current one", len(segments), segments[0].first)
+	}
+
+	// Moves logged after the compaction are replayed on the sessions it saved
+	if err := session.Game.MakeMove(2, 0, PlayerX); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+	restored, _ := openLogManager(t, dir, 2)
+	if _, err := restored.GetSession(idle.ID); err != nil {
+		t.Errorf("idle session not restored: %v", err)
+	}
+	got, err := restored.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("session not restored: %v", err)
+	}
+	if moves := len(got.Game.GetState().Moves); moves != 5 {
+		t.Errorf("restored %d moves, want 5", moves)
+	}
+}
+
+func TestLogStoreRestoresUnsavedSession(t *testing.T) {
+	dir := t.TempDir()
+	m, _ := openLogManager(t, dir, 0)
+	id, err := m.CreateSession(WithBestOf(3))
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	session, _ := m.GetSession(id)
+	x, _, _ := session.JoinSession()
+	o, _, _ := session.JoinSession()
+	winTopRow(t, session, PlayerX)
+	if err := session.Rematch(); err != nil {
+		t.Fatalf("Rematch: %v", err)
+	}
+	playMoves(t, session, Position{1, 1})
+	// The server crashed after logging the session's creation, before its file was written
+	if err := os.Remove(filepath.Join(dir, id+".json")); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, _ := openLogManager(t, dir, 0)
+	got, err := restored.GetSession(id)
+	if err != nil {
+		t.Fatalf("session not restored: %v", err)
+	}
+	if got.FormatBoard() != session.FormatBoard() {
+		t.Errorf("restored session:\n%s\nwant:\n%s", got.FormatBoard(), session.FormatBoard())
+	}
+	// The tokens still work, though the log only holds their hashes
+	for token, want := range map[PlayerToken]Player{x: PlayerO, o: PlayerX} {
+		if player, err := got.GetPlayer(token); err != nil || player != want {
+			t.Errorf("token of %s resolves to %q, %v", want, player, err)
+		}
+	}
+	for key := range got.Players {
+		if _, err := got.GetPlayer(key); err == nil {
+			t.Errorf("the hash %s is accepted as a token", key)
+		}
+	}
+	if err := got.SetFirstMove(o, FirstMoveO); err == nil {
+		t.Error("the second player to join changed the first-move rule")
+	}
+	if err := got.SetFirstMove(x, FirstMoveO); err != nil {
+		t.Errorf("the host cannot change the first-move rule: %v", err)
+	}
+}
+
+func TestLogStoreReplaysSessionActions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []SessionOption
+		play func(t *testing.T, session *Session, x, o PlayerToken) error
+	}{
+		{"takeback", nil, func(t *testing.T, session *Session, x, o PlayerToken) error {
+			playMoves(t, session, Position{1, 1}, Position{0, 0}, Position{2, 2})
+			if err := session.Game.RequestUndo(PlayerX); err != nil {
+				return err
+			}
+			_, err := session.Game.AcceptUndo(PlayerO)
+			return err
+		}},
+		{"resignation", nil, func(t *testing.T, session *Session, x, o PlayerToken) error {
+			playMoves(t, session, Position{1, 1})
+			return session.Game.Resign(PlayerO)
+		}},
+		{"reset", []SessionOption{WithFirstMove(FirstMoveLoser)}, func(t *testing.T, session *Session, x, o PlayerToken) error {
+			winTopRow(t, session, PlayerX)
+			if err := session.Reset(); err != nil {
+				return err
+			}
+			session.Game.StartGame()
+			return session.Game.MakeMove(1, 1, PlayerO)
+		}},
+		{"rematch", []SessionOption{WithBestOf(5)}, func(t *testing.T, session *Session, x, o PlayerToken) error {
+			winTopRow(t, session, PlayerO)
+			if err := session.Rematch(); err != nil {
+				return err
+			}
+			return session.Game.MakeMove(1, 1, PlayerX)
+		}},
+		{"first-move choice", nil, func(t *testing.T, session *Session, x, o PlayerToken) error {
+			if err := session.SetFirstMove(x, FirstMoveCoinToss); err != nil {
+				return err
+			}
+			turn := session.Game.GetState().Turn
+			return session.Game.MakeMove(1, 1, turn)
+		}},
+		{"quantum collapse", []SessionOption{WithVariant(VariantQuantum)}, func(t *testing.T, session *Session, x, o PlayerToken) error {
+			g := session.Game.(*QuantumTicTacToe)
+			if err := g.MakeQuantumMove(Position{0, 0}, Position{0, 1}, PlayerX); err != nil {
+				return err
+			}
+			if err := g.MakeQuantumMove(Position{0, 1}, Position{0, 0}, PlayerO); err != nil {
+				return err
+			}
+			return g.Collapse(Position{0, 0}, PlayerX)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			m, _ := openLogManager(t, dir, 0)
+			session, x, o := startLoggedGame(t, m, tt.opts...)
+			// Everything after the players joined is only logged: the session is not saved again before the "crash"
+			if err := tt.play(t, session, x, o); err != nil {
+				t.Fatalf("playing the session: %v", err)
+			}
+
+			restored, _ := openLogManager(t, dir, 0)
+			got, err := restored.GetSession(session.ID)
+			if err != nil {
+				t.Fatalf("session not restored: %v", err)
+			}
+			if got.FormatBoard() != session.FormatBoard() {
+				t.Errorf("restored session:\n%s\nwant:\n%s", got.FormatBoard(), session.FormatBoard())
+			}
+			gotRule, gotFirst := got.FirstMove()
+			wantRule, wantFirst := session.FirstMove()
+			if gotRule != wantRule || gotFirst != wantFirst {
+				t.Errorf("restored first move %s (%s first), want %s (%s first)", gotRule, gotFirst, wantRule, wantFirst)
+			}
+			if gotNext, wantNext := got.Snapshot().NextFirstPlayer, session.Snapshot().NextFirstPlayer; gotNext != wantNext {
+				t.Errorf("restored next first player %q, want %q", gotNext, wantNext)
+			}
+			if len(got.Game.GetState().Moves) != len(session.Game.GetState().Moves) {
+				t.Errorf("restored %d moves, want %d", len(got.Game.GetState().Moves), len(session.Game.GetState().Moves))
+			}
+		})
+	}
+}
+
+// appendFile appends data to the file at path
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatalf("append to %s: %v", path, err)
+	}
+}
@@ -0,0 +1,162 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened in a session
+type EventType string
+
+const (
+	// EventPlayerJoined fires when a player or bot takes a seat
+	EventPlayerJoined EventType = "player_joined"
+	// EventGameStarted fires when a game starts, once every seat is filled or after a reset or rematch
+	EventGameStarted EventType = "game_started"
+	// EventMoveMade fires after each accepted move, including moves played by bots and by the server
+	EventMoveMade EventType = "move_made"
+	// EventGameOver fires when a game ends, on the board or off it (resignation, agreement, time or forfeit)
+	EventGameOver EventType = "game_over"
+	// EventReset fires when the board is reset for a new game
+	EventReset EventType = "reset"
+	// EventRematch fires when the players swap sides for a rematch, before the board is reset
+	EventRematch EventType = "rematch"
+	// EventTakeback fires when a takeback request is accepted and moves are taken back
+	EventTakeback EventType = "takeback"
+	// EventCollapse fires when the cycle of spooky marks in a quantum tic-tac-toe game collapses
+	EventCollapse EventType = "collapse"
+	// EventFirstMove fires when the host changes the session's first-move rule
+	EventFirstMove EventType = "first_move"
+)
+
+// Event describes something that happened in a session
+type Event struct {
+	Type      EventType `json:"type"`
+	SessionID string    `json:"session_id"`
+	Time      time.Time `json:"time"`
+	// Player is the player who joined, moved, chose the collapse or had their move taken back, the winner of
+	// a game_over event ("" for draws), or who moves first under the rule set by a first_move event
+	// ("" for rules that depend on the last game)
+	Player Player `json:"player,omitempty"`
+	// Move is the move made, for move_made events, or the move that closed the cycle, for collapse events
+	Move *Move `json:"move,omitempty"`
+	// Status is the final status of the game, for game_over events
+	Status Status `json:"status,omitempty"`
+	// Undone is the number of moves taken back, for takeback events
+	Undone int `json:"undone,omitempty"`
+}
+
+// Hook is called with the events it subscribed to
+// Hooks run one event at a time, in order, on a goroutine of their own, so they may call back into
+// the session (e.g. GetState) but see its state as of when they run rather than when the event fired
+type Hook func(Event)
+
+// WithHook subscribes hook to events of the given types, or to every event when no type is given
+func WithHook(hook Hook, types ...EventType) ManagerOption {
+	return func(c *ManagerConfig) {
+		if len(types) == 0 {
+			c.Hooks = append(c.Hooks, hook)
+			return
+		}
+		wanted := make(map[EventType]bool, len(types))
+		for _, t := range types {
+			wanted[t] = true
+		}
+		c.Hooks = append(c.Hooks, func(event Event) {
+			if wanted[event.Type] {
+				hook(event)
+			}
+		})
+	}
+}
+
+// Observable is implemented by engines that report their events
+type Observable interface {
+	// SetObserver registers observe to be called with each game event, under the engine's lock,
+	// so it must not call back into the engine
+	SetObserver(observe func(Event))
+}
+
+// SetObserver registers observe to be called with each game event, under the engine's lock
+func (b *baseEngine) SetObserver(observe func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.observe = observe
+}
+
+// emit reports an event to the observer, unless the engine is replaying moves it has already reported
+// Must be called with the lock held
+func (b *baseEngine) emit(event Event) {
+	if b.observe != nil && !b.replaying {
+		b.observe(event)
+	}
+}
+
+// eventBus delivers events to hooks in order, without blocking the code that publishes them
+type eventBus struct {
+	hooks   []Hook
+	pending []Event
+	wake    chan struct{}
+	mu      sync.Mutex
+}
+
+// newEventBus starts delivering events to hooks, or returns nil when there are none
+func newEventBus(hooks []Hook) *eventBus {
+	if len(hooks) == 0 {
+		return nil
+	}
+	bus := &eventBus{hooks: hooks, wake: make(chan struct{}, 1)}
+	go bus.run()
+	return bus
+}
+
+// publish queues event for delivery
+func (e *eventBus) publish(event Event) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.pending = append(e.pending, event)
+	e.mu.Unlock()
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+		// A delivery is already due and will pick the event up
+	}
+}
+
+// run delivers queued events to every hook until the process exits
+func (e *eventBus) run() {
+	for range e.wake {
+		e.mu.Lock()
+		events := e.pending
+		e.pending = nil
+		e.mu.Unlock()
+
+		for _, event := range events {
+			for _, hook := range e.hooks {
+				hook(event)
+			}
+		}
+	}
+}
+
+// publish stamps event with the session and time, records it if the store records events,
+// and queues it for the manager's hooks
+// Called under the lock the change was made with, so the record is written before anything else can happen
+func (s *Session) publish(event Event) {
+	s.publishRecord(&LogRecord{Event: event})
+}
+
+// publishRecord publishes an event along with the details the event log needs to replay it, see LogRecord
+func (s *Session) publishRecord(record *LogRecord) {
+	record.SessionID = s.ID
+	record.Time = time.Now()
+	if s.recorder != nil {
+		if seq := s.recorder.Record(record); seq != 0 {
+			s.logSeq.Store(seq)
+		}
+	}
+	s.events.publish(record.Event)
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"errors"
+	"time"
+)
+
+// touch records activity in the session now
+// Safe to call with or without the session's lock, and from the engine's observer
+func (s *Session) touch() {
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+// Touch records activity in the session, such as someone viewing the board, keeping it from expiring
+func (s *Session) Touch() {
+	s.touch()
+}
+
+// LastActive returns when the session last saw a join, a move or a board view
+func (s *Session) LastActive() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
+// ExpiresAt returns when the session expires if nothing else happens in it: the idle timeout after
+// its last activity, or the shorter grace period once its game is over. Zero if sessions never expire.
+func (s *Session) ExpiresAt() time.Time {
+	if s.config == nil || s.config.IdleTimeout <= 0 {
+		return time.Time{}
+	}
+	timeout := s.config.IdleTimeout
+	if s.config.FinishedGracePeriod > 0 && s.Game.GetState().Status.IsOver() {
+		timeout = min(timeout, s.config.FinishedGracePeriod)
+	}
+	return s.LastActive().Add(timeout)
+}
+
+// observe receives the engine's events, counting moves (and the game starting, ending or being reset)
+// as activity before passing them on to the hooks
+// Called under the engine's lock, so it must not call back into the engine
+func (s *Session) observe(event Event) {
+	s.touch()
+	s.publish(event)
+}
+
+// ExpireIdleSessions removes every session that has been idle for longer than the manager's idle timeout,
+// or for longer than its grace period once the game is over
+func (m *Manager) ExpireIdleSessions() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var errs []error
+	for _, session := range m.store.List() {
+		if expiresAt := session.ExpiresAt(); !expiresAt.IsZero() && now.After(expiresAt) {
+			if err := m.store.Delete(session.ID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
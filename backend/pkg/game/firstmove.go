@@ -0,0 +1,160 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// FirstMove is the rule that decides which player moves first in each game of a session
+type FirstMove string
+
+const (
+	// FirstMoveX lets X move first in every game
+	FirstMoveX FirstMove = "x"
+	// FirstMoveO lets O move first in every game
+	FirstMoveO FirstMove = "o"
+	// FirstMoveCoinToss picks the first player at random for every game
+	FirstMoveCoinToss FirstMove = "random"
+	// FirstMoveAlternate lets X move first in the first game, then the other player than last game
+	FirstMoveAlternate FirstMove = "alternate"
+	// FirstMoveLoser lets X move first in the first game, then the loser of the last game
+	// (after a draw the players alternate)
+	FirstMoveLoser FirstMove = "loser"
+)
+
+// ParseFirstMove parses a first-move rule (x, o, random, alternate or loser); an empty string selects x
+func ParseFirstMove(rule string) (FirstMove, error) {
+	switch strings.ToLower(strings.TrimSpace(rule)) {
+	case "", "x":
+		return FirstMoveX, nil
+	case "o":
+		return FirstMoveO, nil
+	case "random", "coin", "toss":
+		return FirstMoveCoinToss, nil
+	case "alternate", "alt":
+		return FirstMoveAlternate, nil
+	case "loser":
+		return FirstMoveLoser, nil
+	default:
+		return "", NewInvalidFirstMoveError(rule)
+	}
+}
+
+// String returns a description of the rule, e.g. "coin toss"
+func (f FirstMove) String() string {
+	switch f {
+	case FirstMoveO:
+		return "O always moves first"
+	case FirstMoveCoinToss:
+		return "coin toss"
+	case FirstMoveAlternate:
+		return "players alternate each game"
+	case FirstMoveLoser:
+		return "loser of the last game starts"
+	default:
+		return "X always moves first"
+	}
+}
+
+// FirstMover is implemented by engines that let either player move first
+type FirstMover interface {
+	// SetFirstPlayer lets player move first from the next Reset,
+	// or straight away if no move has been played yet and the game was not set up from a position
+	SetFirstPlayer(player Player)
+}
+
+// SetFirstPlayer lets player move first from the next Reset, or straight away if no move has been played yet
+func (b *baseEngine) SetFirstPlayer(player Player) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.firstPlayer = player
+
+	if b.start == nil && len(b.state.Moves) == 0 && !b.state.Status.IsOver() {
+		// Settle any time spent so far before the other clock starts running
+		b.tickClock()
+		b.state.Turn = player
+		b.state.FirstPlayer = player
+	}
+}
+
+// firstPlayerOf decides who moves first in the first game under rule, among the session's seats
+func firstPlayerOf(rule FirstMove, seats []Player) Player {
+	switch rule {
+	case FirstMoveO:
+		return PlayerO
+	case FirstMoveCoinToss:
+		return seats[rand.Intn(len(seats))]
+	default:
+		return PlayerX
+	}
+}
+
+// nextFirstPlayer decides who moves first in the game after finished under rule
+// Alternating and loser-starts follow the people rather than the symbols, so swapped says whether
+// the players are about to move on to the next symbol
+// With more than two players the first move goes round, and loser-starts gives it to the player after the winner
+func nextFirstPlayer(rule FirstMove, finished *GameState, seats []Player, swapped bool) Player {
+	next := nextPlayer(finished.Players, finished.FirstPlayer)
+	switch rule {
+	case FirstMoveAlternate:
+	case FirstMoveLoser:
+		if winner := finished.Status.Winner(); winner != "" {
+			next = nextPlayer(finished.Players, winner)
+		}
+	default:
+		return firstPlayerOf(rule, seats)
+	}
+	if swapped {
+		return nextPlayer(finished.Players, next)
+	}
+	return next
+}
+
+// FirstMove returns the session's first-move rule and the player who moves first in the current game
+func (s *Session) FirstMove() (FirstMove, Player) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.firstMove, s.Game.GetState().FirstPlayer
+}
+
+// SetFirstMove changes the session's first-move rule; only the host (the first player to join) may change it
+// The rule decides the next game, and the current one too if it has no moves yet (alternating and
+// loser-starts only take effect from the next game). If the bot is now to move, the caller should call PlayBotTurn.
+func (s *Session) SetFirstMove(token PlayerToken, rule FirstMove) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.playerKey(token); !ok || key != s.host {
+		return NewNotHostError()
+	}
+	if _, ok := s.Game.(FirstMover); !ok {
+		return NewFirstMoveUnsupportedError(s.Game.GetState().Variant)
+	}
+
+	var first Player
+	if rule != FirstMoveAlternate && rule != FirstMoveLoser {
+		first = firstPlayerOf(rule, s.seats)
+	}
+	s.setFirstMove(rule, first)
+	s.publishRecord(&LogRecord{Event: Event{Type: EventFirstMove, Player: first}, FirstMove: rule})
+	return nil
+}
+
+// setFirstMove changes the session's first-move rule and lets first move first, unless first is ""
+// Must be called with the lock held (or before the session is shared)
+func (s *Session) setFirstMove(rule FirstMove, first Player) {
+	s.firstMove = rule
+	if mover, ok := s.Game.(FirstMover); ok && first != "" {
+		mover.SetFirstPlayer(first)
+	}
+}
+
+// prepareNextGame applies the first-move rule before the board is reset for the next game
+// Must be called with the lock held
+func (s *Session) prepareNextGame(swapped bool) {
+	mover, ok := s.Game.(FirstMover)
+	if !ok || s.firstMove == "" {
+		return
+	}
+	mover.SetFirstPlayer(nextFirstPlayer(s.firstMove, s.Game.GetState(), s.seats, swapped))
+}
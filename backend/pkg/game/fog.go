@@ -0,0 +1,233 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PlayerViewer is implemented by engines with hidden information, where each player sees a different view of the game
+// GetState and GetStateJSON return the full state for the server's own use; responses must go through the views
+type PlayerViewer interface {
+	// StateFor returns the state as player sees it; "" gets the public state spectators see
+	StateFor(player Player) *GameState
+
+	// FormatBoardFor returns the board as player sees it; "" gets the public board
+	FormatBoardFor(player Player) string
+}
+
+// StateFor returns the game state as player sees it; only games with hidden information show players
+// different states, and "" gets the public state
+func (s *Session) StateFor(player Player) *GameState {
+	if viewer, ok := s.Game.(PlayerViewer); ok {
+		return viewer.StateFor(player)
+	}
+	return s.Game.GetState()
+}
+
+// FogOfWar implements the Engine interface for fog-of-war m,n,k games
+// Each player sees only their own marks and the opponent marks they have run into: a move onto a hidden
+// opponent mark places nothing, reveals that mark to the mover and ends their turn. Spectators only see
+// the revealed cells until the game is over.
+type FogOfWar struct {
+	baseEngine
+	config BoardConfig
+}
+
+// FogState holds what each player of a fog-of-war game has found out about the opponent's marks
+type FogState struct {
+	// Revealed holds, for each player, the opponent marks their blocked moves ran into
+	Revealed map[Player][]Position `json:"revealed"`
+}
+
+// clone returns a deep copy of the fog state
+func (f *FogState) clone() *FogState {
+	if f == nil {
+		return nil
+	}
+	revealed := make(map[Player][]Position, len(f.Revealed))
+	for player, cells := range f.Revealed {
+		revealed[player] = append([]Position{}, cells...)
+	}
+	return &FogState{Revealed: revealed}
+}
+
+// NewFogOfWar creates a new fog-of-war game with the given board configuration
+func NewFogOfWar(config BoardConfig) (*FogOfWar, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	g := &FogOfWar{config: config}
+	g.state = g.newState()
+	return g, nil
+}
+
+// newState returns an empty, pending state for the configured board
+func (g *FogOfWar) newState() *GameState {
+	return &GameState{
+		Variant:     VariantFog,
+		Board:       newBoard(g.config.Rows, g.config.Cols),
+		Rows:        g.config.Rows,
+		Cols:        g.config.Cols,
+		WinLength:   g.config.WinLength,
+		Turn:        PlayerX,
+		FirstPlayer: PlayerX,
+		Status:      StatusPending,
+		Fog: &FogState{
+			Revealed: map[Player][]Position{PlayerX: {}, PlayerO: {}},
+		},
+	}
+}
+
+// MakeMove attempts to make a move at the specified position
+// A move onto a hidden opponent mark is recorded as blocked and reveals the mark instead
+func (g *FogOfWar) MakeMove(row, col int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+	return g.placeMark(row, col, player)
+}
+
+// placeMark places player's mark at (row, col), or reveals the hidden opponent mark there,
+// then updates the status and turn
+// Must be called with the lock held, after checkCanMove
+func (g *FogOfWar) placeMark(row, col int, player Player) error {
+	if row < 0 || row >= g.state.Rows || col < 0 || col >= g.state.Cols {
+		return NewInvalidPositionError(row, col, g.state.Rows, g.state.Cols)
+	}
+
+	cell := Position{Row: row, Col: col}
+	switch mark := g.state.Board[row][col]; {
+	case mark == player || (mark != "" && g.revealedTo(player, cell)):
+		return ErrPositionTaken
+	case mark != "":
+		// The turn is spent finding the opponent's mark
+		g.state.Fog.Revealed[player] = append(g.state.Fog.Revealed[player], cell)
+		g.appendMove(Move{Player: player, Row: row, Col: col, Blocked: true})
+		g.switchTurn()
+		return nil
+	}
+
+	g.state.Board[row][col] = player
+	g.recordMove(row, col, player)
+
+	if line := lineThrough(g.state.Board, row, col, player, g.state.WinLength); line != nil {
+		g.state.WinningLine = line
+		g.endGame(winStatus(player))
+	} else if isFull(g.state.Board) {
+		g.endGame(StatusDraw)
+	} else {
+		g.switchTurn()
+	}
+	return nil
+}
+
+// revealedTo reports whether player has run into the opponent mark at cell
+// Must be called with the lock held
+func (g *FogOfWar) revealedTo(player Player, cell Position) bool {
+	for _, p := range g.state.Fog.Revealed[player] {
+		if p == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// StateFor returns the state as player sees it: their own marks and the opponent marks they have revealed,
+// with the cells of the opponent's unseen moves withheld. Spectators ("") see every revealed mark.
+// Once the game is over everyone sees the whole board.
+func (g *FogOfWar) StateFor(player Player) *GameState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	state := g.state.Clone()
+	if state.Status.IsOver() {
+		return state
+	}
+	if player != "" {
+		state.Fog.Revealed = map[Player][]Position{player: state.Fog.Revealed[player]}
+	}
+
+	visible := func(row, col int) bool {
+		if g.state.Board[row][col] == player {
+			return true
+		}
+		for _, cells := range state.Fog.Revealed {
+			if onLine(cells, row, col) {
+				return true
+			}
+		}
+		return false
+	}
+	for row := range state.Board {
+		for col := range state.Board[row] {
+			if !visible(row, col) {
+				state.Board[row][col] = ""
+			}
+		}
+	}
+	for i, move := range state.Moves {
+		if move.Player != player && !visible(move.Row, move.Col) {
+			state.Moves[i] = Move{Number: move.Number, Player: move.Player, Row: -1, Col: -1, Timestamp: move.Timestamp, Reason: move.Reason, Hidden: true}
+		}
+	}
+	return state
+}
+
+// GetStateJSON returns the public state as a JSON string
+func (g *FogOfWar) GetStateJSON() string {
+	jsonData, _ := json.Marshal(g.StateFor(""))
+	return string(jsonData)
+}
+
+// AcceptUndo accepts the opponent's pending takeback request and returns the number of moves undone
+func (g *FogOfWar) AcceptUndo(player Player) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.acceptUndo(g, player)
+}
+
+// replayMove applies a recorded move, blocked or not, to the current state
+// Must be called with the lock held
+func (g *FogOfWar) replayMove(move Move) error {
+	return g.placeMark(move.Row, move.Col, move.Player)
+}
+
+// Reset resets the game to its initial state
+func (g *FogOfWar) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// Reset to pending - the caller should call StartGame() if both players are still in
+	g.state = g.initialState(g)
+	g.resetClock()
+	g.emit(Event{Type: EventReset})
+}
+
+// FormatBoard returns the public board, as spectators see it
+func (g *FogOfWar) FormatBoard() string {
+	return g.FormatBoardFor("")
+}
+
+// FormatBoardFor returns a human-readable string representation of the board as player sees it
+func (g *FogOfWar) FormatBoardFor(player Player) string {
+	state := g.StateFor(player)
+	var sb strings.Builder
+	sb.WriteString("\n")
+	writeGrid(&sb, state.Board, state.WinningLine)
+	switch {
+	case state.Status.IsOver():
+		sb.WriteString("Fog of war: game over, all marks revealed\n")
+	case player == "":
+		sb.WriteString("Fog of war: spectators only see marks the players have run into\n")
+	default:
+		sb.WriteString(fmt.Sprintf("Fog of war: you see your marks and the %d opponent mark(s) you ran into\n", len(state.Fog.Revealed[player])))
+	}
+	if g.config != DefaultBoardConfig {
+		sb.WriteString(fmt.Sprintf("Board: %dx%d, %d in a row wins\n", state.Rows, state.Cols, state.WinLength))
+	}
+	writeStatusLines(&sb, state)
+	return sb.String()
+}
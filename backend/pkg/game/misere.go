@@ -0,0 +1,32 @@
+package game
+
+// MisereRules is implemented by engines that can be played under misère rules,
+// where completing a line loses instead of winning
+type MisereRules interface {
+	// SetMisere switches the game to misère rules; the game must not have started yet
+	SetMisere() error
+}
+
+// SetMisere switches the game to misère rules, which also apply to every later game in the session
+func (g *TicTacToe) SetMisere() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state.Status != StatusPending || len(g.state.Moves) > 0 {
+		return NewGameInProgressError()
+	}
+	g.misere = true
+	g.state.Misere = true
+	if g.start != nil {
+		g.start.Misere = true
+	}
+	return nil
+}
+
+// completedLineStatus returns the status of a misère or Notakto game lost by completing a line
+func completedLineStatus(player Player) Status {
+	if player == PlayerX {
+		return StatusXCompletedLine
+	}
+	return StatusOCompletedLine
+}
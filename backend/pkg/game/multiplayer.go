@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Seat count limits
+const (
+	MinPlayers = 2
+	MaxPlayers = 4
+)
+
+// PlayerSymbols lists the default symbols in the order seats are handed out and turns go round
+var PlayerSymbols = []Player{PlayerX, PlayerO, PlayerTriangle, PlayerSquare}
+
+// MultiPlayer is implemented by engines that can seat more than two players
+type MultiPlayer interface {
+	// SetPlayers seats players (2-4), who take turns in the given order, see seatSymbols
+	// Must be called before the game starts
+	SetPlayers(players []Player) error
+}
+
+// SetPlayers seats players, who take turns round-robin in the given order
+func (g *TicTacToe) SetPlayers(players []Player) error {
+	if n := len(players); n < MinPlayers || n > MaxPlayers {
+		return NewInvalidPlayerCountError(n)
+	}
+	if g.misere && len(players) > 2 {
+		return NewMultiplayerUnsupportedError("misère rules")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.players = nil
+	if len(players) > 2 {
+		g.players = players
+	}
+	g.state.Players = g.players
+	return nil
+}
+
+// seatSymbols returns the symbols of n seats: X and O, then symbols for the extra seats, or Δ and □ if nil
+// Only the extra seats can be given other symbols, as the rules of two-player games are written for X and O
+func seatSymbols(n int, symbols []Player) ([]Player, error) {
+	if n < MinPlayers || n > MaxPlayers {
+		return nil, NewInvalidPlayerCountError(n)
+	}
+	if symbols == nil {
+		return PlayerSymbols[:n], nil
+	}
+	if len(symbols) != n-2 {
+		return nil, NewInvalidSymbolsError(fmt.Sprintf("%d players need one symbol for each seat after X and O, got %d", n, len(symbols)))
+	}
+
+	players := append([]Player{PlayerX, PlayerO}, symbols...)
+	for i, symbol := range symbols {
+		r, size := utf8.DecodeRuneInString(string(symbol))
+		if size == 0 || size != len(symbol) || !unicode.IsGraphic(r) || unicode.IsSpace(r) || r == '_' {
+			return nil, NewInvalidSymbolsError(fmt.Sprintf("%q is not a single visible character other than _", symbol))
+		}
+		// Marks off a winning line are shown in lower case, so symbols must differ in more than case
+		for _, taken := range players[:i+2] {
+			if strings.EqualFold(string(taken), string(symbol)) {
+				return nil, NewInvalidSymbolsError(fmt.Sprintf("%q is taken", symbol))
+			}
+		}
+	}
+	return players, nil
+}
+
+// joinPlayers lists players separated by commas, e.g. "X, O, Δ"
+func joinPlayers(players []Player) string {
+	names := make([]string, len(players))
+	for i, player := range players {
+		names[i] = string(player)
+	}
+	return strings.Join(names, ", ")
+}
+
+// nextPlayer returns the player after player in the turn order, nil order meaning X and O
+func nextPlayer(order []Player, player Player) Player {
+	for i, p := range order {
+		if p == player {
+			return order[(i+1)%len(order)]
+		}
+	}
+	return otherPlayer(player)
+}
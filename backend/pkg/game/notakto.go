@@ -0,0 +1,240 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Notakto board count limits
+const (
+	DefaultNotaktoBoards = 3
+	MaxNotaktoBoards     = 9
+)
+
+// Notakto implements the Engine interface for Notakto, misère tic-tac-toe on several 3x3 boards
+// Both players place X. A board dies once it has three in a row and takes no more marks,
+// and whoever completes the line that kills the last live board loses.
+// Board holds the boards side by side as a 3 x 3N grid (board b is columns 3b to 3b+2),
+// so MakeMove takes global coordinates; MakeSubBoardMove takes a board and a cell (0-8).
+type Notakto struct {
+	baseEngine
+	boards int
+}
+
+// NotaktoState holds which boards of a Notakto game are dead
+type NotaktoState struct {
+	Boards int `json:"boards"`
+	// Dead holds, by board number, whether the board has three in a row
+	Dead []bool `json:"dead"`
+}
+
+// NewNotakto creates a new Notakto game on the given number of boards
+func NewNotakto(boards int) (*Notakto, error) {
+	if boards < 1 || boards > MaxNotaktoBoards {
+		return nil, NewInvalidBoardCountError(boards)
+	}
+	g := &Notakto{boards: boards}
+	g.state = g.newState()
+	return g, nil
+}
+
+// newState returns an empty, pending Notakto state
+func (g *Notakto) newState() *GameState {
+	return &GameState{
+		Variant:     VariantNotakto,
+		Board:       newBoard(3, 3*g.boards),
+		Rows:        3,
+		Cols:        3 * g.boards,
+		WinLength:   3,
+		Turn:        PlayerX,
+		FirstPlayer: PlayerX,
+		Status:      StatusPending,
+		Notakto: &NotaktoState{
+			Boards: g.boards,
+			Dead:   make([]bool, g.boards),
+		},
+	}
+}
+
+// NotaktoCell converts a board number and cell number (0-8) to global coordinates
+func NotaktoCell(board, cell int) (row, col int) {
+	return cell / 3, board*3 + cell%3
+}
+
+// NotaktoBoardCell converts global coordinates to a board number and cell number
+func NotaktoBoardCell(row, col int) (board, cell int) {
+	return col / 3, row*3 + col%3
+}
+
+// MakeMove attempts to make a move at the specified global position
+// The mark placed is always X, whichever player places it
+func (g *Notakto) MakeMove(row, col int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+
+	if row < 0 || row >= g.state.Rows || col < 0 || col >= g.state.Cols {
+		return NewInvalidPositionError(row, col, g.state.Rows, g.state.Cols)
+	}
+
+	return g.placeMark(row, col, player)
+}
+
+// MakeSubBoardMove places an X in cell (0-8) of board
+func (g *Notakto) MakeSubBoardMove(board, cell int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+
+	if board < 0 || board >= g.boards || cell < 0 || cell >= 9 {
+		return NewInvalidSubBoardMoveError(board, cell, g.boards)
+	}
+
+	row, col := NotaktoCell(board, cell)
+	return g.placeMark(row, col, player)
+}
+
+// placeMark places an X for player at (row, col), then updates the dead boards, status and turn
+// Must be called with the lock held, after checkCanMove
+func (g *Notakto) placeMark(row, col int, player Player) error {
+	board, _ := NotaktoBoardCell(row, col)
+	if g.state.Notakto.Dead[board] {
+		return NewBoardDeadError(board)
+	}
+	if g.state.Board[row][col] != "" {
+		return ErrPositionTaken
+	}
+
+	g.state.Board[row][col] = PlayerX
+	g.recordMove(row, col, player)
+
+	// The board dies with its first line; the last board to die decides the game
+	local := g.localBoard(board)
+	if line := lineThrough(local, row, col%3, PlayerX, 3); line != nil {
+		g.state.Notakto.Dead[board] = true
+		if g.liveBoards() == 0 {
+			for i := range line {
+				line[i].Col += board * 3
+			}
+			g.state.WinningLine = line
+			g.endGame(completedLineStatus(player))
+			return nil
+		}
+	}
+
+	g.switchTurn()
+	return nil
+}
+
+// localBoard returns the 3x3 cells of board
+// Must be called with the lock held
+func (g *Notakto) localBoard(board int) [][]Player {
+	local := make([][]Player, 3)
+	for row := range local {
+		local[row] = g.state.Board[row][board*3 : board*3+3]
+	}
+	return local
+}
+
+// liveBoards returns the number of boards without three in a row
+// Must be called with the lock held
+func (g *Notakto) liveBoards() int {
+	live := 0
+	for _, dead := range g.state.Notakto.Dead {
+		if !dead {
+			live++
+		}
+	}
+	return live
+}
+
+// AcceptUndo accepts the opponent's pending takeback request and returns the number of moves undone
+func (g *Notakto) AcceptUndo(player Player) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.acceptUndo(g, player)
+}
+
+// replayMove applies a recorded move (in global coordinates) to the current state
+// Must be called with the lock held
+func (g *Notakto) replayMove(move Move) error {
+	return g.placeMark(move.Row, move.Col, move.Player)
+}
+
+// legalMoves returns every empty cell of the live boards
+func (g *Notakto) legalMoves() []Position {
+	if g.state.Status != StatusPlaying {
+		return nil
+	}
+	var moves []Position
+	for row := range g.state.Board {
+		for col, cell := range g.state.Board[row] {
+			if cell == "" && !g.state.Notakto.Dead[col/3] {
+				moves = append(moves, Position{Row: row, Col: col})
+			}
+		}
+	}
+	return moves
+}
+
+// snapshot returns an independent copy of the game
+func (g *Notakto) snapshot() searchable {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return &Notakto{baseEngine: baseEngine{state: g.cloneForSearch(), firstPlayer: g.firstPlayer}, boards: g.boards}
+}
+
+// Reset resets the game to its initial state
+func (g *Notakto) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// Reset to pending - the caller should call StartGame() if both players are still in
+	g.state = g.initialState(g)
+	g.resetClock()
+	g.emit(Event{Type: EventReset})
+}
+
+// FormatBoard returns a human-readable string representation of the boards, side by side
+// Dead boards are shown in lower case, except for the line that lost the game
+func (g *Notakto) FormatBoard() string {
+	state := g.GetState()
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for board := 0; board < state.Notakto.Boards; board++ {
+		if board > 0 {
+			sb.WriteString("   ")
+		}
+		sb.WriteString(fmt.Sprintf("%-5d", board))
+	}
+	sb.WriteString("\n")
+	for i, row := range state.Board {
+		for col, cell := range row {
+			if col > 0 && col%3 == 0 {
+				sb.WriteString(" | ")
+			} else if col > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(cellSymbol(cell, !state.Notakto.Dead[col/3] || onLine(state.WinningLine, i, col)))
+		}
+		sb.WriteString("\n")
+	}
+
+	var live []string
+	for board, dead := range state.Notakto.Dead {
+		if !dead {
+			live = append(live, fmt.Sprint(board))
+		}
+	}
+	sb.WriteString("Notakto: both players place X, completing the last line loses\n")
+	if len(live) > 0 {
+		sb.WriteString(fmt.Sprintf("Live boards: %d of %d (board %s)\n", len(live), state.Notakto.Boards, strings.Join(live, ", ")))
+	}
+	writeStatusLines(&sb, state)
+	return sb.String()
+}
@@ -0,0 +1,264 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Compact notation
+//
+// A position is written as its cells in row-major order followed by the player to move,
+// e.g. "XO_X_O___:O" ("_" for an empty cell). Rows may be separated by "/" for readability,
+// and "-" may replace ":" so the notation fits in a DNS label. Letters are case-insensitive.
+//
+// A move list names cells by column letter and row number, counted from the top-left cell a1,
+// e.g. "b2 a1 c3" (centre, top-left, bottom-right on a 3x3 board).
+
+// PositionLoader is implemented by engines that can start a game from a position
+type PositionLoader interface {
+	// LoadPosition replaces the board and the player to move; the position must not be decided yet
+	LoadPosition(board [][]Player, turn Player) error
+}
+
+// MoveLoader is implemented by engines that can start a game by replaying a list of moves
+type MoveLoader interface {
+	// LoadMoves replays moves from the empty board, players alternating from the game's first player
+	// (X unless another was set with SetFirstPlayer); the game must not be decided yet
+	LoadMoves(moves []Position) error
+}
+
+// FormatPosition writes the state's board and player to move in compact notation
+// Boards other than 3x3 have their rows separated by "/"
+func FormatPosition(state *GameState) string {
+	var sb strings.Builder
+	for i, row := range state.Board {
+		if i > 0 && (state.Rows != 3 || state.Cols != 3) {
+			sb.WriteString("/")
+		}
+		for _, cell := range row {
+			sb.WriteString(cellSymbol(cell, true))
+		}
+	}
+	sb.WriteString(":")
+	sb.WriteString(string(state.Turn))
+	return sb.String()
+}
+
+// ParsePosition parses a position in compact notation for a rows x cols board
+func ParsePosition(notation string, rows, cols int) ([][]Player, Player, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(notation))
+	cells, side := normalized, ""
+	if i := strings.LastIndexAny(normalized, ":-"); i >= 0 {
+		cells, side = normalized[:i], normalized[i+1:]
+	}
+	cells = strings.ReplaceAll(cells, "/", "")
+
+	if len(cells) != rows*cols {
+		return nil, "", NewInvalidNotationError(notation, fmt.Sprintf("expected %d cells for a %dx%d board, got %d", rows*cols, rows, cols, len(cells)))
+	}
+
+	board := newBoard(rows, cols)
+	counts := map[Player]int{}
+	for i, c := range cells {
+		switch c {
+		case 'X', 'O':
+			board[i/cols][i%cols] = Player(string(c))
+			counts[Player(string(c))]++
+		case '_', '.':
+		default:
+			return nil, "", NewInvalidNotationError(notation, fmt.Sprintf("invalid cell %q (expected X, O or _)", c))
+		}
+	}
+
+	// Without an explicit side, X moves whenever both players have placed the same number of marks
+	turn := Player(side)
+	switch turn {
+	case PlayerX, PlayerO:
+	case "":
+		turn = PlayerX
+		if counts[PlayerX] > counts[PlayerO] {
+			turn = PlayerO
+		}
+	default:
+		return nil, "", NewInvalidNotationError(notation, fmt.Sprintf("invalid player to move %q (expected X or O)", side))
+	}
+
+	// Players take turns, so the player to move has placed as many marks as the other player (if they moved first)
+	// or one fewer (if the other player did)
+	if other := counts[otherPlayer(turn)]; other != counts[turn] && other != counts[turn]+1 {
+		return nil, "", NewInvalidNotationError(notation, fmt.Sprintf("%d X and %d O cannot happen with %s to move", counts[PlayerX], counts[PlayerO], turn))
+	}
+	return board, turn, nil
+}
+
+// SquareName returns the move-list name of a cell, e.g. "b2" for row 1, column 1
+func SquareName(row, col int) string {
+	return fmt.Sprintf("%c%d", 'a'+col, row+1)
+}
+
+// FormatMoveList writes moves as space-separated square names
+// Quantum tic-tac-toe spooky marks are written as both squares joined by "+", e.g. "a1+c3"
+func FormatMoveList(moves []Move) string {
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		if move.Hidden {
+			names[i] = "?"
+			continue
+		}
+		names[i] = SquareName(move.Row, move.Col)
+		if move.Second != nil {
+			names[i] += "+" + SquareName(move.Second.Row, move.Second.Col)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// ParseMoveList parses square names separated by spaces or "-"
+func ParseMoveList(notation string) ([]Position, error) {
+	fields := strings.FieldsFunc(strings.ToLower(notation), func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+	if len(fields) == 0 {
+		return nil, NewInvalidNotationError(notation, "empty move list")
+	}
+
+	moves := make([]Position, len(fields))
+	for i, field := range fields {
+		var col rune
+		var row int
+		var rest string
+		if n, _ := fmt.Sscanf(field, "%c%d%s", &col, &row, &rest); n != 2 || col < 'a' || col > 'z' || row < 1 {
+			return nil, NewInvalidNotationError(notation, fmt.Sprintf("invalid square %q (expected e.g. b2)", field))
+		}
+		moves[i] = Position{Row: row - 1, Col: int(col - 'a')}
+	}
+	return moves, nil
+}
+
+// LoadPosition replaces the board and the player to move; the position must not be decided yet
+// The game stays pending until the players have joined, and Reset and takebacks return to this position
+func (g *TicTacToe) LoadPosition(board [][]Player, turn Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loadPosition(board, turn)
+}
+
+// loadPosition checks and installs a position on a fresh state
+// Must be called with the lock held
+func (g *TicTacToe) loadPosition(board [][]Player, turn Player) error {
+	if g.state.Status != StatusPending || len(g.state.Moves) > 0 {
+		return NewGameInProgressError()
+	}
+	if len(board) != g.config.Rows || len(board[0]) != g.config.Cols {
+		return NewInvalidNotationError(fmt.Sprintf("%dx%d board", len(board), len(board[0])), fmt.Sprintf("board must be %dx%d", g.config.Rows, g.config.Cols))
+	}
+	if hasLine(board, PlayerX, g.config.WinLength) || hasLine(board, PlayerO, g.config.WinLength) || isFull(board) {
+		return NewDecidedPositionError()
+	}
+
+	state := g.newState()
+	state.Board = board
+	state.Turn = turn
+	state.FirstPlayer = turn
+	state.Start = FormatPosition(state)
+	g.start = state.Clone()
+	g.state = state
+	g.resetClock()
+	return nil
+}
+
+// LoadMoves replays moves from the empty board, players alternating from the game's first player
+func (g *TicTacToe) LoadMoves(moves []Position) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loadMoves(g, moves)
+}
+
+// LoadPosition replaces the board and the player to move; pieces must rest on the bottom row or another piece
+func (g *ConnectFour) LoadPosition(board [][]Player, turn Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for row := 0; row < len(board)-1; row++ {
+		for col, cell := range board[row] {
+			if cell != "" && col < len(board[row+1]) && board[row+1][col] == "" {
+				return NewFloatingPieceError(row, col, row+1)
+			}
+		}
+	}
+	return g.loadPosition(board, turn)
+}
+
+// LoadMoves replays moves from the empty board, players alternating from the game's first player
+func (g *ConnectFour) LoadMoves(moves []Position) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loadMoves(g, moves)
+}
+
+// replayMove applies a recorded move, which must land on the lowest free row of its column
+// Must be called with the lock held
+func (g *ConnectFour) replayMove(move Move) error {
+	landing, err := g.landingRow(move.Col)
+	if err != nil {
+		return err
+	}
+	if move.Row != landing {
+		return NewFloatingPieceError(move.Row, move.Col, landing)
+	}
+	return g.placeMark(move.Row, move.Col, move.Player)
+}
+
+// LoadMoves replays moves from the empty board, players alternating from the game's first player
+func (g *UltimateTicTacToe) LoadMoves(moves []Position) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loadMoves(g, moves)
+}
+
+// loadMoves replays moves on a fresh state, starting with its first player (see initialState),
+// leaving the game pending until the players have joined
+// On failure the state is left untouched
+// Must be called with the lock held
+func (b *baseEngine) loadMoves(r replayer, moves []Position) error {
+	if b.state.Status != StatusPending || len(b.state.Moves) > 0 {
+		return NewGameInProgressError()
+	}
+
+	b.replaying = true
+	defer func() { b.replaying = false }()
+
+	previous := b.state
+	b.state = b.initialState(r)
+	b.state.Status = StatusPlaying
+	for _, move := range moves {
+		err := r.replayMove(Move{Player: b.state.Turn, Row: move.Row, Col: move.Col})
+		if err == nil && b.state.Status != StatusPlaying {
+			err = NewDecidedPositionError()
+		}
+		if err != nil {
+			b.state = previous
+			return err
+		}
+	}
+	b.state.Status = StatusPending
+	b.state.TurnStarted = time.Time{}
+	b.resetClock()
+	return nil
+}
+
+// initialState returns the state a new game starts from: the loaded start position, if any,
+// or else the variant's empty board with the chosen first player to move
+// Must be called with the lock held
+func (b *baseEngine) initialState(r replayer) *GameState {
+	if b.start != nil {
+		return b.start.Clone()
+	}
+	state := r.newState()
+	if b.firstPlayer != "" {
+		state.Turn = b.firstPlayer
+		state.FirstPlayer = b.firstPlayer
+	}
+	return state
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		notation string
+		turn     Player
+	}{
+		{"_________", PlayerX},
+		{"x________", PlayerO},
+		{"XO_X_O___:X", PlayerX},
+		{"xo_x_o___-o", PlayerO}, // O moved first
+		{"o________:x", PlayerX},
+		{"o________", PlayerX},
+		{"xo_/x__/___", PlayerO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			_, turn, err := ParsePosition(tt.notation, 3, 3)
+			if err != nil {
+				t.Fatalf("ParsePosition: %v", err)
+			}
+			if turn != tt.turn {
+				t.Errorf("turn = %s, want %s", turn, tt.turn)
+			}
+		})
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	tests := []string{
+		"XXX______:O", // three X and no O
+		"x________:x", // X cannot move twice in a row
+		"o________:o", // nor can O
+		"OO_O_X___",   // O two marks ahead
+		"XO_______:Z", // unknown player
+		"XO_A_____",   // unknown mark
+		"XO_______X",  // too many cells
+		"XO_/X__:O",   // too few cells
+	}
+	for _, notation := range tests {
+		t.Run(notation, func(t *testing.T) {
+			_, _, err := ParsePosition(notation, 3, 3)
+			var gameErr *Error
+			if !errors.As(err, &gameErr) || gameErr.Code != ErrCodeInvalidNotation {
+				t.Errorf("ParsePosition(%q) = %v, want an invalid notation error", notation, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package game
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -27,6 +30,19 @@ func (t PlayerToken) String() string {
 	return string(t)
 }
 
+// tokenHashPrefix starts the hashes the event log holds instead of player tokens, see hashToken
+const tokenHashPrefix = "sha256:"
+
+// hashToken returns the hash of a player token, which the event log records instead of the token
+// Hashes are returned as they are
+func hashToken(token PlayerToken) PlayerToken {
+	if strings.HasPrefix(string(token), tokenHashPrefix) {
+		return token
+	}
+	sum := sha256.Sum256([]byte(token))
+	return PlayerToken(tokenHashPrefix + hex.EncodeToString(sum[:]))
+}
+
 // PlayerInfo represents information about a player in a session
 type PlayerInfo struct {
 	Token  PlayerToken
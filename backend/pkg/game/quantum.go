@@ -0,0 +1,468 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QuantumMover is implemented by engines whose moves place a mark in two cells at once
+type QuantumMover interface {
+	// MakeQuantumMove places the player's spooky mark in two different cells
+	// Returns an error if the move is invalid
+	MakeQuantumMove(first, second Position, player Player) error
+
+	// Collapse settles a pending collapse by choosing the cell the cycle-closing mark collapses into
+	// Only the player who did not close the cycle may choose
+	Collapse(cell Position, player Player) error
+}
+
+// QuantumTicTacToe implements the Engine interface for quantum tic-tac-toe on a 3x3 board
+// Each move places a spooky mark in two cells, entangling them. When a move closes a cycle of
+// entangled cells, the other player chooses which of its two cells the closing mark collapses into,
+// and every mark in the cycle (and any hanging off it) collapses into a classical mark. Only classical
+// marks count towards three in a row; when both players complete a line in the same collapse, the line
+// whose latest mark is older wins 1 point and the other 1/2.
+// Board holds the classical marks, Quantum the spooky marks and the move number of each classical mark.
+type QuantumTicTacToe struct {
+	baseEngine
+}
+
+// QuantumState holds the spooky marks and collapses of a quantum tic-tac-toe game
+type QuantumState struct {
+	// SpookyMarks holds the marks that have not collapsed yet, each in two cells
+	SpookyMarks []SpookyMark `json:"spooky_marks"`
+	// Subscripts holds the move number of each cell's classical mark, 0 for cells without one
+	Subscripts [][]int `json:"subscripts"`
+	// Collapse is the collapse the player to move must settle before moving, nil if none
+	Collapse *PendingCollapse `json:"collapse,omitempty"`
+	// Scores holds each player's points once the game is won: 1 for a line (2 for two at once),
+	// 1/2 for the later of two lines completed by different players in the same collapse
+	Scores map[Player]float64 `json:"scores,omitempty"`
+}
+
+// SpookyMark is a mark in superposition between two cells
+type SpookyMark struct {
+	Player Player      `json:"player"`
+	Number int         `json:"number"`
+	Cells  [2]Position `json:"cells"`
+}
+
+// PendingCollapse is a cycle of entangled cells waiting for the chooser to decide how it collapses
+type PendingCollapse struct {
+	// Mark is the number of the move that closed the cycle
+	Mark int `json:"mark"`
+	// Chooser is the player who picks which of Cells the mark collapses into
+	Chooser Player      `json:"chooser"`
+	Cells   [2]Position `json:"cells"`
+}
+
+// clone returns a deep copy of the quantum state
+func (q *QuantumState) clone() *QuantumState {
+	if q == nil {
+		return nil
+	}
+	quantumCopy := *q
+	quantumCopy.SpookyMarks = append([]SpookyMark{}, q.SpookyMarks...)
+	quantumCopy.Subscripts = make([][]int, len(q.Subscripts))
+	for i, row := range q.Subscripts {
+		quantumCopy.Subscripts[i] = append([]int(nil), row...)
+	}
+	if q.Collapse != nil {
+		collapseCopy := *q.Collapse
+		quantumCopy.Collapse = &collapseCopy
+	}
+	if q.Scores != nil {
+		quantumCopy.Scores = make(map[Player]float64, len(q.Scores))
+		for player, score := range q.Scores {
+			quantumCopy.Scores[player] = score
+		}
+	}
+	return &quantumCopy
+}
+
+// NewQuantumTicTacToe creates a new quantum tic-tac-toe game instance
+func NewQuantumTicTacToe() *QuantumTicTacToe {
+	g := &QuantumTicTacToe{}
+	g.state = g.newState()
+	return g
+}
+
+// newState returns an empty, pending quantum state
+func (g *QuantumTicTacToe) newState() *GameState {
+	subscripts := make([][]int, 3)
+	for i := range subscripts {
+		subscripts[i] = make([]int, 3)
+	}
+	return &GameState{
+		Variant:     VariantQuantum,
+		Board:       newBoard(3, 3),
+		Rows:        3,
+		Cols:        3,
+		WinLength:   3,
+		Turn:        PlayerX,
+		FirstPlayer: PlayerX,
+		Status:      StatusPending,
+		Quantum: &QuantumState{
+			SpookyMarks: []SpookyMark{},
+			Subscripts:  subscripts,
+		},
+	}
+}
+
+// MakeMove places a classical mark, which is only allowed in the last cell without one
+// All other moves are quantum moves, see MakeQuantumMove
+func (g *QuantumTicTacToe) MakeMove(row, col int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanPlace(player); err != nil {
+		return err
+	}
+	return g.placeClassical(Position{Row: row, Col: col}, player)
+}
+
+// MakeQuantumMove places the player's spooky mark in two different cells without a classical mark
+func (g *QuantumTicTacToe) MakeQuantumMove(first, second Position, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanPlace(player); err != nil {
+		return err
+	}
+	return g.placeSpooky(first, second, player)
+}
+
+// Collapse settles the pending collapse by choosing the cell the cycle-closing mark collapses into
+func (g *QuantumTicTacToe) Collapse(cell Position, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+	if g.state.Quantum.Collapse == nil {
+		return ErrNoCollapse
+	}
+	return g.resolveCollapse(cell)
+}
+
+// checkCanPlace checks that player may place a mark: it is their turn and no collapse is waiting
+// Must be called with the lock held
+func (g *QuantumTicTacToe) checkCanPlace(player Player) error {
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+	if pending := g.state.Quantum.Collapse; pending != nil {
+		return NewCollapsePendingError(pending)
+	}
+	return nil
+}
+
+// placeSpooky places player's spooky mark in first and second, then hands the turn over
+// If the mark closes a cycle of entangled cells, the opponent must settle the collapse before moving
+// Must be called with the lock held, after checkCanPlace
+func (g *QuantumTicTacToe) placeSpooky(first, second Position, player Player) error {
+	for _, cell := range []Position{first, second} {
+		if cell.Row < 0 || cell.Row >= 3 || cell.Col < 0 || cell.Col >= 3 {
+			return NewInvalidPositionError(cell.Row, cell.Col, 3, 3)
+		}
+		if g.state.Board[cell.Row][cell.Col] != "" {
+			return ErrPositionTaken
+		}
+	}
+	if first == second {
+		return NewQuantumMoveError("a spooky mark needs two different cells")
+	}
+	if len(g.openCells()) < 2 {
+		return NewQuantumMoveError("only one cell is left: play it with move-ROW-COL")
+	}
+
+	cycle := g.entangled(first, second)
+	number := len(g.state.Moves) + 1
+	g.state.Quantum.SpookyMarks = append(g.state.Quantum.SpookyMarks, SpookyMark{
+		Player: player,
+		Number: number,
+		Cells:  [2]Position{first, second},
+	})
+	g.appendMove(Move{Player: player, Row: first.Row, Col: first.Col, Second: &second})
+
+	g.switchTurn()
+	if cycle {
+		g.state.Quantum.Collapse = &PendingCollapse{
+			Mark:    number,
+			Chooser: g.state.Turn,
+			Cells:   [2]Position{first, second},
+		}
+	}
+	return nil
+}
+
+// placeClassical places player's classical mark in the last cell without one, ending the game
+// Must be called with the lock held, after checkCanPlace
+func (g *QuantumTicTacToe) placeClassical(cell Position, player Player) error {
+	if cell.Row < 0 || cell.Row >= 3 || cell.Col < 0 || cell.Col >= 3 {
+		return NewInvalidPositionError(cell.Row, cell.Col, 3, 3)
+	}
+	if g.state.Board[cell.Row][cell.Col] != "" {
+		return ErrPositionTaken
+	}
+	if len(g.openCells()) > 1 {
+		return NewQuantumMoveError("moves take two cells: use move-ROW-COL-ROW-COL")
+	}
+
+	g.state.Board[cell.Row][cell.Col] = player
+	g.state.Quantum.Subscripts[cell.Row][cell.Col] = len(g.state.Moves) + 1
+	g.recordMove(cell.Row, cell.Col, player)
+	g.settle()
+	return nil
+}
+
+// resolveCollapse collapses the pending cycle's closing mark into cell, and every mark entangled with it
+// The chooser then moves next.
+// Must be called with the lock held
+func (g *QuantumTicTacToe) resolveCollapse(cell Position) error {
+	pending := g.state.Quantum.Collapse
+	if cell != pending.Cells[0] && cell != pending.Cells[1] {
+		return NewInvalidCollapseError(cell, pending)
+	}
+
+	// Each collapsed mark forces the other marks in its cell into their other cell
+	type landing struct {
+		mark int
+		cell Position
+	}
+	queue := []landing{{pending.Mark, cell}}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		mark, ok := g.takeSpookyMark(next.mark)
+		if !ok || g.state.Board[next.cell.Row][next.cell.Col] != "" {
+			continue
+		}
+		g.state.Board[next.cell.Row][next.cell.Col] = mark.Player
+		g.state.Quantum.Subscripts[next.cell.Row][next.cell.Col] = mark.Number
+		for _, other := range g.state.Quantum.SpookyMarks {
+			if other.Cells[0] == next.cell {
+				queue = append(queue, landing{other.Number, other.Cells[1]})
+			} else if other.Cells[1] == next.cell {
+				queue = append(queue, landing{other.Number, other.Cells[0]})
+			}
+		}
+	}
+
+	g.state.Quantum.Collapse = nil
+	collapsed := cell
+	g.state.Moves[pending.Mark-1].CollapsedTo = &collapsed
+	move := g.state.Moves[pending.Mark-1]
+	g.emit(Event{Type: EventCollapse, Player: pending.Chooser, Move: &move})
+	g.settle()
+	return nil
+}
+
+// takeSpookyMark removes the spooky mark with the given move number and returns it
+// Must be called with the lock held
+func (g *QuantumTicTacToe) takeSpookyMark(number int) (SpookyMark, bool) {
+	marks := g.state.Quantum.SpookyMarks
+	for i, mark := range marks {
+		if mark.Number == number {
+			g.state.Quantum.SpookyMarks = append(append([]SpookyMark{}, marks[:i]...), marks[i+1:]...)
+			return mark, true
+		}
+	}
+	return SpookyMark{}, false
+}
+
+// entangled reports whether a and b are already connected through spooky marks,
+// so that a mark in both would close a cycle
+// Must be called with the lock held
+func (g *QuantumTicTacToe) entangled(a, b Position) bool {
+	seen := map[Position]bool{a: true}
+	queue := []Position{a}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		if cell == b {
+			return true
+		}
+		for _, mark := range g.state.Quantum.SpookyMarks {
+			for i, end := range mark.Cells {
+				if end == cell && !seen[mark.Cells[1-i]] {
+					seen[mark.Cells[1-i]] = true
+					queue = append(queue, mark.Cells[1-i])
+				}
+			}
+		}
+	}
+	return false
+}
+
+// openCells returns the cells without a classical mark
+// Must be called with the lock held
+func (g *QuantumTicTacToe) openCells() []Position {
+	var cells []Position
+	for row := range g.state.Board {
+		for col, cell := range g.state.Board[row] {
+			if cell == "" {
+				cells = append(cells, Position{Row: row, Col: col})
+			}
+		}
+	}
+	return cells
+}
+
+// quantumLine is a line of classical marks and the move number of its latest mark
+type quantumLine struct {
+	player Player
+	cells  []Position
+	latest int
+}
+
+// settle scores the classical lines after a collapse or the last classical mark and ends the game
+// if anyone has a line or the board is full
+// Must be called with the lock held
+func (g *QuantumTicTacToe) settle() {
+	lines := g.classicalLines()
+	if len(lines) == 0 {
+		if len(g.openCells()) == 0 {
+			g.endGame(StatusDraw)
+		}
+		return
+	}
+
+	// The line completed by the oldest mark wins; a later line by the other player only earns half a point
+	sort.Slice(lines, func(i, j int) bool { return lines[i].latest < lines[j].latest })
+	winner := lines[0].player
+	scores := map[Player]float64{PlayerX: 0, PlayerO: 0}
+	for _, line := range lines {
+		if line.player == winner {
+			scores[winner]++
+		} else {
+			scores[line.player] = 0.5
+		}
+	}
+	g.state.Quantum.Scores = scores
+	g.state.WinningLine = lines[0].cells
+	g.endGame(winStatus(winner))
+}
+
+// classicalLines returns every line of three classical marks of the same player
+// Must be called with the lock held
+func (g *QuantumTicTacToe) classicalLines() []quantumLine {
+	var lines []quantumLine
+	for _, player := range seats {
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				for _, dir := range lineDirections {
+					// Only count each line once, from its first cell
+					if lineLength(g.state.Board, player, row, col, dir) < 3 || lineLength(g.state.Board, player, row-dir[0], col-dir[1], dir) > 0 {
+						continue
+					}
+					line := quantumLine{player: player, cells: lineCells(row, col, dir, 3)}
+					for _, cell := range line.cells {
+						if n := g.state.Quantum.Subscripts[cell.Row][cell.Col]; n > line.latest {
+							line.latest = n
+						}
+					}
+					lines = append(lines, line)
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// AcceptUndo accepts the opponent's pending takeback request and returns the number of moves undone
+func (g *QuantumTicTacToe) AcceptUndo(player Player) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.acceptUndo(g, player)
+}
+
+// replayMove applies a recorded move to the current state, including the collapse it triggered
+// Must be called with the lock held
+func (g *QuantumTicTacToe) replayMove(move Move) error {
+	if move.Second == nil {
+		return g.placeClassical(Position{Row: move.Row, Col: move.Col}, move.Player)
+	}
+	if err := g.placeSpooky(Position{Row: move.Row, Col: move.Col}, *move.Second, move.Player); err != nil {
+		return err
+	}
+	if move.CollapsedTo != nil {
+		return g.resolveCollapse(*move.CollapsedTo)
+	}
+	return nil
+}
+
+// Reset resets the game to its initial state
+func (g *QuantumTicTacToe) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// Reset to pending - the caller should call StartGame() if both players are still in
+	g.state = g.initialState(g)
+	g.resetClock()
+	g.emit(Event{Type: EventReset})
+}
+
+// FormatBoard returns a human-readable string representation of the board
+// Classical marks are upper case with their move number (X3), spooky marks lower case (x1,o2)
+func (g *QuantumTicTacToe) FormatBoard() string {
+	state := g.GetState()
+	quantum := state.Quantum
+
+	cells := make([][]string, 3)
+	width := 1
+	for row := range state.Board {
+		cells[row] = make([]string, 3)
+		for col, mark := range state.Board[row] {
+			text := "_"
+			if mark != "" {
+				// Classical marks stay upper case to tell them from spooky marks; the winning line is listed below
+				text = string(mark) + strconv.Itoa(quantum.Subscripts[row][col])
+			} else {
+				var spooky []string
+				for _, m := range quantum.SpookyMarks {
+					if m.Cells[0] == (Position{Row: row, Col: col}) || m.Cells[1] == (Position{Row: row, Col: col}) {
+						spooky = append(spooky, strings.ToLower(string(m.Player))+strconv.Itoa(m.Number))
+					}
+				}
+				if len(spooky) > 0 {
+					text = strings.Join(spooky, ",")
+				}
+			}
+			cells[row][col] = text
+			if len(text) > width {
+				width = len(text)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for _, row := range cells {
+		for col, text := range row {
+			sb.WriteString(fmt.Sprintf("%-*s", width, text))
+			if col < len(row)-1 {
+				sb.WriteString(" | ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Quantum: x1,o2 are spooky marks (move 1 by X, move 2 by O), X3 a collapsed mark\n")
+	if pending := quantum.Collapse; pending != nil {
+		sb.WriteString(fmt.Sprintf("Collapse: %s chooses where %s%d lands: %s or %s (collapse-ROW-COL)\n", pending.Chooser,
+			strings.ToLower(string(otherPlayer(pending.Chooser))), pending.Mark, pending.Cells[0], pending.Cells[1]))
+	}
+	if quantum.Scores != nil {
+		sb.WriteString(fmt.Sprintf("Scores: X %s - O %s\n", formatScore(quantum.Scores[PlayerX]), formatScore(quantum.Scores[PlayerO])))
+	}
+	writeStatusLines(&sb, state)
+	return sb.String()
+}
+
+// formatScore formats a quantum score, e.g. 1 or 0.5
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
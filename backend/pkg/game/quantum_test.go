@@ -0,0 +1,195 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// newQuantumGame starts a quantum game and plays spooky marks alternately for X and O, starting with X
+func newQuantumGame(t *testing.T, marks ...[2]Position) *QuantumTicTacToe {
+	t.Helper()
+	g := NewQuantumTicTacToe()
+	g.StartGame()
+	for i, mark := range marks {
+		player := PlayerX
+		if i%2 == 1 {
+			player = PlayerO
+		}
+		if err := g.MakeQuantumMove(mark[0], mark[1], player); err != nil {
+			t.Fatalf("move %d: %v", i+1, err)
+		}
+	}
+	return g
+}
+
+func TestQuantumCycleWaitsForCollapse(t *testing.T) {
+	g := newQuantumGame(t, [2]Position{{0, 0}, {0, 1}}, [2]Position{{0, 1}, {0, 0}})
+
+	pending := g.GetState().Quantum.Collapse
+	if pending == nil {
+		t.Fatal("closing a cycle left no collapse to settle")
+	}
+	if pending.Mark != 2 || pending.Chooser != PlayerX {
+		t.Errorf("collapse of mark %d chosen by %s, want mark 2 chosen by X", pending.Mark, pending.Chooser)
+	}
+
+	if err := g.MakeQuantumMove(Position{1, 0}, Position{1, 1}, PlayerX); err == nil {
+		t.Error("move made before the collapse was settled")
+	}
+	if err := g.Collapse(Position{0, 0}, PlayerO); err == nil {
+		t.Error("the player who closed the cycle chose its collapse")
+	}
+	var gameErr *Error
+	if err := g.Collapse(Position{1, 1}, PlayerX); !errors.As(err, &gameErr) {
+		t.Errorf("collapse into a cell outside the cycle: %v", err)
+	}
+}
+
+func TestQuantumCollapse(t *testing.T) {
+	// X1 and O2 close a cycle in the top row, X3 and O4 another across the diagonal
+	g := newQuantumGame(t,
+		[2]Position{{0, 0}, {0, 1}},
+		[2]Position{{0, 1}, {0, 0}},
+	)
+	if err := g.Collapse(Position{0, 0}, PlayerX); err != nil {
+		t.Fatalf("Collapse: %v", err)
+	}
+	if err := g.MakeQuantumMove(Position{1, 1}, Position{2, 2}, PlayerX); err != nil {
+		t.Fatalf("move after collapse: %v", err)
+	}
+	if err := g.MakeQuantumMove(Position{1, 1}, Position{2, 2}, PlayerO); err != nil {
+		t.Fatalf("cycle-closing move: %v", err)
+	}
+	if err := g.Collapse(Position{2, 2}, PlayerX); err != nil {
+		t.Fatalf("Collapse: %v", err)
+	}
+
+	state := g.GetState()
+	want := map[Position]struct {
+		player    Player
+		subscript int
+	}{
+		{0, 0}: {PlayerO, 2},
+		{0, 1}: {PlayerX, 1},
+		{2, 2}: {PlayerO, 4},
+		{1, 1}: {PlayerX, 3},
+	}
+	for cell, mark := range want {
+		if got := state.Board[cell.Row][cell.Col]; got != mark.player {
+			t.Errorf("cell %v holds %q, want %s", cell, got, mark.player)
+		}
+		if got := state.Quantum.Subscripts[cell.Row][cell.Col]; got != mark.subscript {
+			t.Errorf("cell %v has subscript %d, want %d", cell, got, mark.subscript)
+		}
+	}
+	if len(state.Quantum.SpookyMarks) != 0 || state.Quantum.Collapse != nil {
+		t.Errorf("spooky marks %v and collapse %v left after the collapses", state.Quantum.SpookyMarks, state.Quantum.Collapse)
+	}
+	if collapsed := state.Moves[3].CollapsedTo; collapsed == nil || *collapsed != (Position{2, 2}) {
+		t.Errorf("move 4 collapsed to %v, want 2,2", collapsed)
+	}
+	// The chooser moves next
+	if state.Turn != PlayerX || state.Status != StatusPlaying {
+		t.Errorf("turn %s, status %s after the collapse; want X playing", state.Turn, state.Status)
+	}
+
+	// Replaying the moves settles the same collapses
+	replayed := NewQuantumTicTacToe()
+	replayed.StartGame()
+	if err := replayed.rebuild(replayed, state.Moves); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if got := replayed.GetState(); !equalBoards(got.Board, state.Board) {
+		t.Errorf("replayed board %v, want %v", got.Board, state.Board)
+	}
+}
+
+func TestQuantumScoring(t *testing.T) {
+	tests := []struct {
+		name       string
+		board      []string
+		subscripts [][]int
+		status     Status
+		scores     map[Player]float64
+	}{
+		{
+			name:       "older line wins, later line earns half",
+			board:      []string{"XXX", "OOO", "___"},
+			subscripts: [][]int{{1, 3, 5}, {2, 4, 6}, {0, 0, 0}},
+			status:     StatusXWins,
+			scores:     map[Player]float64{PlayerX: 1, PlayerO: 0.5},
+		},
+		{
+			name:       "O's line is older",
+			board:      []string{"XXX", "OOO", "___"},
+			subscripts: [][]int{{1, 3, 7}, {2, 4, 6}, {0, 0, 0}},
+			status:     StatusOWins,
+			scores:     map[Player]float64{PlayerX: 0.5, PlayerO: 1},
+		},
+		{
+			name:       "two lines at once",
+			board:      []string{"XXX", "XO_", "XO_"},
+			subscripts: [][]int{{1, 3, 5}, {7, 2, 0}, {9, 4, 0}},
+			status:     StatusXWins,
+			scores:     map[Player]float64{PlayerX: 2, PlayerO: 0},
+		},
+		{
+			name:       "full board without a line",
+			board:      []string{"XOX", "XOO", "OXX"},
+			subscripts: [][]int{{1, 2, 3}, {5, 4, 6}, {8, 7, 9}},
+			status:     StatusDraw,
+		},
+		{
+			name:       "no line yet",
+			board:      []string{"XO_", "___", "___"},
+			subscripts: [][]int{{1, 2, 0}, {0, 0, 0}, {0, 0, 0}},
+			status:     StatusPlaying,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewQuantumTicTacToe()
+			g.StartGame()
+			for row, line := range tt.board {
+				for col, mark := range line {
+					if mark != '_' {
+						g.state.Board[row][col] = Player(mark)
+					}
+				}
+			}
+			g.state.Quantum.Subscripts = tt.subscripts
+			g.settle()
+
+			state := g.GetState()
+			if state.Status != tt.status {
+				t.Errorf("status = %s, want %s", state.Status, tt.status)
+			}
+			if len(state.Quantum.Scores) != len(tt.scores) {
+				t.Fatalf("scores = %v, want %v", state.Quantum.Scores, tt.scores)
+			}
+			for player, score := range tt.scores {
+				if got := state.Quantum.Scores[player]; got != score {
+					t.Errorf("%s scored %v, want %v", player, got, score)
+				}
+			}
+		})
+	}
+}
+
+// equalBoards reports whether two boards hold the same marks
+func equalBoards(a, b [][]Player) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for row := range a {
+		if len(a[row]) != len(b[row]) {
+			return false
+		}
+		for col := range a[row] {
+			if a[row][col] != b[row][col] {
+				return false
+			}
+		}
+	}
+	return true
+}
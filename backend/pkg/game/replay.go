@@ -0,0 +1,96 @@
+package game
+
+// Replay is the position after a given move of a game, for stepping through the game move by move
+type Replay struct {
+	// Number is the move replayed, 0 for the starting position
+	Number int
+	// Total is the number of moves in the replayed game
+	Total int
+	// Move is the move that produced the position, nil for the starting position
+	Move *Move
+	// Previous is true when the replayed game is the one played before the last reset or rematch
+	Previous bool
+	// Board is the position rendered like the engine's FormatBoard
+	Board string
+}
+
+// Replay rebuilds the position after move number (0 for the starting position) of the current game,
+// or of the previous game while the current one has no moves yet
+// Games with hidden information show the position as viewer saw it then ("" for spectators)
+func (s *Session) Replay(number int, viewer Player) (*Replay, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, previous := s.Game.GetState(), false
+	if len(state.Moves) == 0 && s.lastGame != nil {
+		state, previous = s.lastGame, true
+	}
+	if number < 0 || number > len(state.Moves) {
+		return nil, NewInvalidReplayError(number, len(state.Moves))
+	}
+
+	// The position is rebuilt on a fresh engine set up like the session's, which is not shared yet,
+	// so its state can be rebuilt without its lock
+	settings := s.settings
+	engine, _, err := newSessionEngine(&settings)
+	if err != nil {
+		return nil, err
+	}
+	g, ok := engine.(restorable)
+	r, rewinds := engine.(rewindable)
+	if !ok || !rewinds {
+		return nil, NewReplayUnsupportedError(state.Variant)
+	}
+	// The replayed game starts with its own first player, whoever moves first in the games after it
+	if mover, ok := engine.(FirstMover); ok && state.FirstPlayer != "" {
+		mover.SetFirstPlayer(state.FirstPlayer)
+	}
+	if number == len(state.Moves) {
+		// The final position is the game as it is, or as it ended (e.g. by resignation)
+		g.restoreState(state.Clone(), state.FirstPlayer)
+	} else if err := r.rebuild(r, state.Moves[:number]); err != nil {
+		return nil, err
+	}
+
+	replay := &Replay{
+		Number:   number,
+		Total:    len(state.Moves),
+		Previous: previous,
+		Board:    engine.FormatBoard(),
+	}
+	moves := state.Moves
+	if view, ok := engine.(PlayerViewer); ok {
+		replay.Board = view.FormatBoardFor(viewer)
+		moves = view.StateFor(viewer).Moves
+	}
+	if number > 0 {
+		move := moves[number-1]
+		replay.Move = &move
+	}
+	return replay, nil
+}
+
+// Reset keeps the current game for replays, counts it in the series if it is over, and resets the board
+// A series that is over cannot be reset, so its result stands
+// The caller should call StartGame if all seats are still filled
+func (s *Session) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if series := s.series(); series.Over {
+		return NewSeriesOverError(series.Winner)
+	}
+	s.recordGame()
+	s.keepLastGame()
+	s.prepareNextGame(false)
+	s.clearVotes()
+	s.Game.Reset()
+	return nil
+}
+
+// keepLastGame keeps the current game for replays, unless no move has been played in it
+// Must be called with the lock held
+func (s *Session) keepLastGame() {
+	if state := s.Game.GetState(); len(state.Moves) > 0 {
+		s.lastGame = state
+	}
+}
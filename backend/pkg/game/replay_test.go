@@ -0,0 +1,146 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	session, _, _ := newStartedSession(t)
+	winTopRow(t, session, PlayerX)
+
+	tests := []struct {
+		number int
+		board  string
+	}{
+		{0, "_ _ _\n_ _ _\n_ _ _\n"},
+		{1, "X _ _\n_ _ _\n_ _ _\n"},
+		{4, "X X _\nO O _\n_ _ _\n"},
+		{5, "X X X\no o _\n_ _ _\n"}, // the winning line stands out in upper case
+	}
+	for _, tt := range tests {
+		replay, err := session.Replay(tt.number, "")
+		if err != nil {
+			t.Fatalf("Replay(%d): %v", tt.number, err)
+		}
+		if replay.Number != tt.number || replay.Total != 5 || replay.Previous {
+			t.Errorf("Replay(%d) = move %d of %d (previous %t)", tt.number, replay.Number, replay.Total, replay.Previous)
+		}
+		if !strings.Contains(replay.Board, tt.board) {
+			t.Errorf("Replay(%d) board:\n%s\nwant:\n%s", tt.number, replay.Board, tt.board)
+		}
+		if (replay.Move == nil) != (tt.number == 0) || (replay.Move != nil && replay.Move.Number != tt.number) {
+			t.Errorf("Replay(%d) move = %+v", tt.number, replay.Move)
+		}
+	}
+
+	// The final position shows how the game ended
+	if replay, _ := session.Replay(5, ""); !strings.Contains(replay.Board, string(StatusXWins)) {
+		t.Errorf("final position does not show the result:\n%s", replay.Board)
+	}
+	// The position before the last move does not
+	if replay, _ := session.Replay(4, ""); !strings.Contains(replay.Board, string(StatusPlaying)) {
+		t.Errorf("position before the last move shows a result:\n%s", replay.Board)
+	}
+
+	var gameErr *Error
+	for _, number := range []int{-1, 6} {
+		if _, err := session.Replay(number, ""); !errors.As(err, &gameErr) || gameErr.Code != ErrCodeInvalidReplay {
+			t.Errorf("Replay(%d): %v, want an invalid replay error", number, err)
+		}
+	}
+}
+
+func TestReplayPreviousGame(t *testing.T) {
+	session, _, _ := newStartedSession(t)
+	winTopRow(t, session, PlayerX)
+	if err := session.Rematch(); err != nil {
+		t.Fatalf("Rematch: %v", err)
+	}
+
+	// Until the new game has a move, replays show the game before the rematch
+	replay, err := session.Replay(3, "")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if !replay.Previous || replay.Total != 5 || !strings.Contains(replay.Board, "X X _\nO _ _\n") {
+		t.Errorf("Replay(3) = %+v, want move 3 of the previous game", replay)
+	}
+
+	if err := session.Game.MakeMove(1, 1, PlayerX); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+	replay, err = session.Replay(1, "")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if replay.Previous || replay.Total != 1 {
+		t.Errorf("Replay(1) = %+v, want the current game", replay)
+	}
+}
+
+func TestReplayStartsWithFirstPlayer(t *testing.T) {
+	session, _, _ := newStartedSession(t, WithFirstMove(FirstMoveO))
+	if err := session.Game.MakeMove(1, 1, PlayerO); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+
+	replay, err := session.Replay(0, "")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if !strings.Contains(replay.Board, "Turn: O") {
+		t.Errorf("start position of a game O opened:\n%s", replay.Board)
+	}
+}
+
+func TestReplayQuantum(t *testing.T) {
+	session, _, _ := newStartedSession(t, WithVariant(VariantQuantum))
+	g := session.Game.(*QuantumTicTacToe)
+	for _, step := range []func() error{
+		func() error { return g.MakeQuantumMove(Position{0, 0}, Position{0, 1}, PlayerX) },
+		func() error { return g.MakeQuantumMove(Position{0, 1}, Position{0, 0}, PlayerO) },
+		func() error { return g.Collapse(Position{0, 0}, PlayerX) },
+		func() error { return g.Resign(PlayerX) },
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("playing the game: %v", err)
+		}
+	}
+
+	replay, err := session.Replay(2, "")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if replay.Move == nil || replay.Move.CollapsedTo == nil || !strings.Contains(replay.Board, "O2") {
+		t.Errorf("Replay(2) = %+v, want the collapsed cycle:\n%s", replay.Move, replay.Board)
+	}
+}
+
+func TestReplayFogShowsViewersBoard(t *testing.T) {
+	session, _, _ := newStartedSession(t, WithVariant(VariantFog))
+	playMoves(t, session, Position{0, 0}, Position{2, 2}, Position{1, 1})
+
+	tests := []struct {
+		viewer Player
+		number int
+		board  string
+	}{
+		{PlayerX, 2, "X _ _\n_ _ _\n_ _ _\n"},
+		{PlayerO, 2, "_ _ _\n_ _ _\n_ _ O\n"},
+		{"", 3, "_ _ _\n_ _ _\n_ _ _\n"},
+	}
+	for _, tt := range tests {
+		replay, err := session.Replay(tt.number, tt.viewer)
+		if err != nil {
+			t.Fatalf("Replay: %v", err)
+		}
+		if !strings.Contains(replay.Board, tt.board) {
+			t.Errorf("Replay(%d) for %q:\n%s\nwant:\n%s", tt.number, tt.viewer, replay.Board, tt.board)
+		}
+	}
+	if replay, _ := session.Replay(1, PlayerO); replay.Move == nil || !replay.Move.Hidden {
+		t.Errorf("O sees X's unseen move as %+v", replay.Move)
+	}
+}
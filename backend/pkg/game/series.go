@@ -0,0 +1,191 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Series is the running score of the games played in a session
+// Scores are keyed by the symbol each player has in the current game, since rematches swap sides
+type Series struct {
+	// BestOf is the number of games in the series, 0 for an open-ended series
+	BestOf int            `json:"best_of,omitempty"`
+	Games  int            `json:"games"`
+	Score  map[Player]int `json:"score"`
+	Draws  int            `json:"draws"`
+	// Winner is the player who clinched the series, if any
+	Winner Player `json:"winner,omitempty"`
+	// Over is true once the series is clinched or all of its games are played
+	Over bool `json:"over"`
+
+	seats []Player // the session's symbols, the order String lists the scores in
+}
+
+// String returns the series score, e.g. "Series (best of 5): X 2 - O 1, 1 draw"
+// Games with more than two players list every symbol, e.g. "X 1 - O 0 - Δ 2"
+func (s *Series) String() string {
+	var sb strings.Builder
+	sb.WriteString("Series")
+	if s.BestOf > 0 {
+		sb.WriteString(fmt.Sprintf(" (best of %d)", s.BestOf))
+	}
+	scores := make([]string, 0, len(s.Score))
+	for _, player := range s.seats {
+		if wins, ok := s.Score[player]; ok {
+			scores = append(scores, fmt.Sprintf("%s %d", player, wins))
+		}
+	}
+	sb.WriteString(": " + strings.Join(scores, " - "))
+	if s.Draws == 1 {
+		sb.WriteString(", 1 draw")
+	} else if s.Draws > 1 {
+		sb.WriteString(fmt.Sprintf(", %d draws", s.Draws))
+	}
+	if s.Winner != "" {
+		sb.WriteString(fmt.Sprintf(" | %s wins the series", s.Winner))
+	} else if s.Over {
+		sb.WriteString(" | series tied")
+	}
+	return sb.String()
+}
+
+// clinched reports whether a player has won more than half of the series' games
+func (s *Series) clinched(wins int) bool {
+	return s.BestOf > 0 && wins > s.BestOf/2
+}
+
+// seriesRecord holds the results of the session's earlier games
+// Wins are keyed by token so they follow players across side swaps
+type seriesRecord struct {
+	bestOf int
+	games  int
+	wins   map[PlayerToken]int
+	draws  int
+}
+
+// Series returns the series score, counting the current game once it is over
+// Returns nil for a session without a best-of setting until its first rematch
+func (s *Session) Series() *Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.record.bestOf == 0 && s.record.games == 0 {
+		return nil
+	}
+	return s.series()
+}
+
+// series builds the series score
+// Must be called with the lock held
+func (s *Session) series() *Series {
+	series := &Series{
+		BestOf: s.record.bestOf,
+		Games:  s.record.games,
+		Score:  make(map[Player]int, len(s.seats)),
+		Draws:  s.record.draws,
+		seats:  s.seats,
+	}
+	for _, player := range s.seats {
+		series.Score[player] = 0
+	}
+	for token, wins := range s.record.wins {
+		series.Score[s.Players[token]] += wins
+	}
+
+	status := s.Game.GetState().Status
+	if status.IsOver() {
+		series.Games++
+		if winner := status.Winner(); winner != "" {
+			series.Score[winner]++
+		} else {
+			series.Draws++
+		}
+	}
+
+	for player, wins := range series.Score {
+		if series.clinched(wins) {
+			series.Winner = player
+		}
+	}
+	series.Over = series.Winner != "" || (series.BestOf > 0 && series.Games >= series.BestOf)
+	return series
+}
+
+// Rematch records the finished game and starts a new one with X and O swapped
+// With more than two players every player moves on to the next symbol (X to O, O to Δ, ..., the last to X)
+// If the bot now plays X, the caller should call PlayBotTurn
+func (s *Session) Rematch() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.Players) < len(s.seats) {
+		return NewGameNotStartedError()
+	}
+	status := s.Game.GetState().Status
+	if !status.IsOver() {
+		return NewGameInProgressError()
+	}
+	if series := s.series(); series.Over {
+		return NewSeriesOverError(series.Winner)
+	}
+
+	s.publish(Event{Type: EventRematch})
+	// Record the finished game before the players swap sides
+	s.recordGame()
+	s.prepareNextGame(true)
+	for token, player := range s.Players {
+		s.Players[token] = s.nextSeat(player)
+	}
+	if s.bot != nil {
+		s.bot = NewBot(otherPlayer(s.bot.Player), s.bot.Difficulty)
+	}
+
+	s.keepLastGame()
+	s.clearVotes()
+	s.Game.Reset()
+	s.Game.StartGame()
+	return nil
+}
+
+// recordGame counts the current game in the series' earlier games, if it is over
+// Must be called with the lock held, before the players swap sides
+func (s *Session) recordGame() {
+	status := s.Game.GetState().Status
+	if !status.IsOver() {
+		return
+	}
+	s.record.games++
+	if winner := status.Winner(); winner != "" {
+		for token, player := range s.Players {
+			if player == winner {
+				s.record.wins[token]++
+			}
+		}
+	} else {
+		s.record.draws++
+	}
+}
+
+// nextSeat returns the symbol after player in seat order, wrapping around to X
+// Must be called with the lock held
+func (s *Session) nextSeat(player Player) Player {
+	return nextPlayer(s.seats, player)
+}
+
+// FormatBoard returns the game's board followed by the series score, if any
+// Games with hidden information show the public board, see FormatBoardFor
+func (s *Session) FormatBoard() string {
+	return s.FormatBoardFor("")
+}
+
+// FormatBoardFor returns the board as player sees it followed by the series score, if any
+// Only games with hidden information show players different boards; "" gets the public board
+func (s *Session) FormatBoardFor(player Player) string {
+	board := s.Game.FormatBoard()
+	if viewer, ok := s.Game.(PlayerViewer); ok {
+		board = viewer.FormatBoardFor(player)
+	}
+	if series := s.Series(); series != nil {
+		return board + series.String() + "\n"
+	}
+	return board
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// newStartedSession creates a session with the given options and seats two players
+func newStartedSession(t *testing.T, opts ...SessionOption) (*Session, PlayerToken, PlayerToken) {
+	t.Helper()
+	m := NewManager()
+	id, err := m.CreateSession(opts...)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	session, _ := m.GetSession(id)
+	first, _, err := session.JoinSession()
+	if err != nil {
+		t.Fatalf("JoinSession: %v", err)
+	}
+	second, _, err := session.JoinSession()
+	if err != nil {
+		t.Fatalf("JoinSession: %v", err)
+	}
+	return session, first, second
+}
+
+// winTopRow plays a game in which winner completes the top row, whoever moves first
+func winTopRow(t *testing.T, session *Session, winner Player) {
+	t.Helper()
+	loser := otherPlayer(winner)
+	moves := []struct {
+		player Player
+		cell   Position
+	}{
+		{winner, Position{0, 0}}, {loser, Position{1, 0}},
+		{winner, Position{0, 1}}, {loser, Position{1, 1}},
+		{winner, Position{0, 2}},
+	}
+	if session.Game.GetState().Turn == loser {
+		if err := session.Game.MakeMove(2, 2, loser); err != nil {
+			t.Fatalf("opening move: %v", err)
+		}
+	}
+	for _, move := range moves {
+		if err := session.Game.MakeMove(move.cell.Row, move.cell.Col, move.player); err != nil {
+			t.Fatalf("%s %v: %v", move.player, move.cell, err)
+		}
+	}
+}
+
+func TestRematchSwapsSides(t *testing.T) {
+	session, first, second := newStartedSession(t)
+	if err := session.Rematch(); err == nil {
+		t.Error("rematch during the game succeeded")
+	}
+
+	winTopRow(t, session, PlayerX)
+	if err := session.Rematch(); err != nil {
+		t.Fatalf("Rematch: %v", err)
+	}
+	for token, want := range map[PlayerToken]Player{first: PlayerO, second: PlayerX} {
+		if player, _ := session.GetPlayer(token); player != want {
+			t.Errorf("player plays %s after the rematch, want %s", player, want)
+		}
+	}
+
+	series := session.Series()
+	if series == nil || series.Games != 1 || series.Score[PlayerO] != 1 || series.Score[PlayerX] != 0 {
+		t.Errorf("series after the rematch = %+v, want the first game's win kept by its winner, now O", series)
+	}
+	if got := session.Game.GetState(); got.Status != StatusPlaying || len(got.Moves) != 0 {
+		t.Errorf("new game is %s with %d moves, want a fresh game", got.Status, len(got.Moves))
+	}
+}
+
+func TestSeriesBestOf(t *testing.T) {
+	session, first, _ := newStartedSession(t, WithBestOf(3))
+	if series := session.Series(); series == nil || series.BestOf != 3 || series.Games != 0 {
+		t.Fatalf("series before the first game = %+v", series)
+	}
+
+	// The first player wins as X, then again as O after the rematch
+	winTopRow(t, session, PlayerX)
+	if err := session.Rematch(); err != nil {
+		t.Fatalf("Rematch: %v", err)
+	}
+	winTopRow(t, session, PlayerO)
+
+	series := session.Series()
+	if player, _ := session.GetPlayer(first); series.Winner != player || !series.Over {
+		t.Errorf("series = %+v, want it clinched by %s", series, player)
+	}
+	if want := "Series (best of 3): X 0 - O 2 | O wins the series"; series.String() != want {
+		t.Errorf("series reads %q, want %q", series.String(), want)
+	}
+
+	var gameErr *Error
+	if err := session.Rematch(); !errors.As(err, &gameErr) || gameErr.Code != ErrCodeSeriesOver {
+		t.Errorf("rematch after the series was clinched: %v", err)
+	}
+}
+
+func TestSeriesCountsDraws(t *testing.T) {
+	session, _, _ := newStartedSession(t)
+	// X O X / X O O / O X X
+	moves := []Position{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 0}, {2, 2}}
+	for i, move := range moves {
+		player := PlayerX
+		if i%2 == 1 {
+			player = PlayerO
+		}
+		if err := session.Game.MakeMove(move.Row, move.Col, player); err != nil {
+			t.Fatalf("move %d: %v", i+1, err)
+		}
+	}
+	if status := session.Game.GetState().Status; status != StatusDraw {
+		t.Fatalf("status = %s, want a draw", status)
+	}
+	if err := session.Rematch(); err != nil {
+		t.Fatalf("Rematch: %v", err)
+	}
+	if want := "Series: X 0 - O 0, 1 draw"; session.Series().String() != want {
+		t.Errorf("series reads %q, want %q", session.Series().String(), want)
+	}
+}
+
+func TestResetCountsFinishedGame(t *testing.T) {
+	session, first, _ := newStartedSession(t, WithBestOf(3))
+	winTopRow(t, session, PlayerX)
+	if err := session.Reset(); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	session.Game.StartGame()
+
+	// The reset keeps the sides, and the finished game stays in the series
+	if player, _ := session.GetPlayer(first); player != PlayerX {
+		t.Errorf("first player plays %s after the reset, want X", player)
+	}
+	if series := session.Series(); series.Games != 1 || series.Score[PlayerX] != 1 {
+		t.Errorf("series after the reset = %+v, want X's win counted", series)
+	}
+
+	// Once the series is clinched, resetting cannot undo it
+	winTopRow(t, session, PlayerX)
+	var gameErr *Error
+	if err := session.Reset(); !errors.As(err, &gameErr) || gameErr.Code != ErrCodeSeriesOver {
+		t.Fatalf("reset after the series was clinched: %v", err)
+	}
+	if status := session.Game.GetState().Status; status != StatusXWins {
+		t.Errorf("clinching game is %s after the refused reset", status)
+	}
+	if series := session.Series(); series.Winner != PlayerX || !series.Over {
+		t.Errorf("series = %+v, want it won by X", series)
+	}
+}
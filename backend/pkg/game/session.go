@@ -2,7 +2,10 @@ package game
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,15 +17,37 @@ type Session struct {
 	Game      Engine
 	Players   map[PlayerToken]Player // Maps player tokens to their assigned player (X or O)
 	CreatedAt time.Time
+	settings  SessionConfig // the options the session was created with, kept to rebuild its engine on restore
 	config    *ManagerConfig
-	mu        sync.RWMutex
+	bot       *Bot
+	solver    *Solver
+	record    seriesRecord
+	lastGame  *GameState    // the game before the last reset or rematch, kept for replays
+	events    *eventBus     // nil when the manager has no hooks
+	recorder  EventRecorder // records events as they happen, nil unless the store does
+	firstMove FirstMove
+	host      PlayerToken  // the first player to join, who may change the session's settings
+	seats     []Player     // the symbols handed out to players, in order; the game starts once all are taken
+	crowd     *votingRound // the votes for the next move of a crowd-play session, nil for other sessions
+	// lastActive is when the session last saw a join, a move or a board view, in Unix nanoseconds;
+	// atomic so the engine's observer can update it while the session's lock is held
+	lastActive atomic.Int64
+	// logSeq is the number of the session's last event log record, see SessionSnapshot.LogSeq
+	logSeq atomic.Uint64
+	mu     sync.RWMutex
 }
 
+// seats lists the players of a two-player game in the order seats are handed out
+var seats = PlayerSymbols[:2]
+
 // Manager manages multiple game sessions
 type Manager struct {
-	sessions map[string]*Session
+	store    SessionStore
 	config   *ManagerConfig
-	mu       sync.RWMutex
+	solver   *Solver // shared by all sessions so solved positions are reused
+	events   *eventBus
+	recorder EventRecorder // the store, if it records events
+	mu       sync.RWMutex  // held while sessions are added or removed, so they are not saved at the same time
 }
 
 // NewManager creates a new session manager with optional configuration
@@ -38,14 +63,38 @@ func NewManager(opts ...ManagerOption) *Manager {
 		opt(config)
 	}
 
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	// Stores that log events hear about every event as it happens, see Session.publish
+	recorder, _ := store.(EventRecorder)
+
 	return &Manager{
-		sessions: make(map[string]*Session),
+		store:    store,
 		config:   config,
+		solver:   NewSolver(),
+		events:   newEventBus(config.Hooks),
+		recorder: recorder,
 	}
 }
 
 // CreateSession creates a new game session and returns its ID
-func (m *Manager) CreateSession() (string, error) {
+// Without options the session plays classic 3x3 tic-tac-toe
+func (m *Manager) CreateSession(opts ...SessionOption) (string, error) {
+	sessionConfig := &SessionConfig{
+		Variant:   VariantTicTacToe,
+		FirstMove: FirstMoveX,
+	}
+	for _, opt := range opts {
+		opt(sessionConfig)
+	}
+
+	engine, sessionSeats, err := newSessionEngine(sessionConfig)
+	if err != nil {
+		return "", err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -60,30 +109,223 @@ func (m *Manager) CreateSession() (string, error) {
 		}
 		shortID = uuidStr[:length]
 		// Ensure uniqueness (very unlikely collision, but check anyway)
-		if _, exists := m.sessions[shortID]; !exists {
+		if _, exists := m.store.Get(shortID); !exists {
 			break
 		}
 	}
 
 	session := &Session{
 		ID:        shortID,
-		Game:      NewTicTacToe(),
+		Game:      engine,
 		Players:   make(map[PlayerToken]Player),
 		CreatedAt: time.Now(),
+		settings:  *sessionConfig,
 		config:    m.config,
+		solver:    m.solver,
+		record: seriesRecord{
+			bestOf: sessionConfig.BestOf,
+			wins:   make(map[PlayerToken]int),
+		},
+		events:    m.events,
+		recorder:  m.recorder,
+		firstMove: sessionConfig.FirstMove,
+		seats:     sessionSeats,
+	}
+	session.touch()
+	if observable, ok := engine.(Observable); ok {
+		observable.SetObserver(session.observe)
 	}
 
-	m.sessions[shortID] = session
+	// A vs-AI session keeps the second seat for the bot, so the first human to join plays X
+	if sessionConfig.Bot != "" {
+		session.seatBot(PlayerO, sessionConfig.Bot)
+	}
+	// A crowd-play session seats the teams straight away, so voting can start
+	if sessionConfig.Crowd {
+		window := sessionConfig.VotingWindow
+		if window == 0 {
+			window = m.config.VotingWindow
+		}
+		if window == 0 {
+			window = DefaultVotingWindow
+		}
+		session.crowd = &votingRound{window: window}
+		session.seatTeams()
+	}
+
+	if err := m.store.Put(session); err != nil {
+		return "", err
+	}
 
 	return shortID, nil
 }
 
+// newSessionEngine creates the engine for a session configuration, set up with every option,
+// and returns it with the symbols of the session's seats
+func newSessionEngine(config *SessionConfig) (Engine, []Player, error) {
+	if config.BestOf < 0 {
+		return nil, nil, NewInvalidSeriesError(config.BestOf)
+	}
+	if config.TimeControl < 0 {
+		return nil, nil, NewInvalidTimeControlError(config.TimeControl)
+	}
+	engine, err := newEngine(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	sessionSeats := seats
+	if config.Players != 0 && config.Players != len(seats) {
+		if err := checkMultiplayerConfig(config); err != nil {
+			return nil, nil, err
+		}
+		players, err := seatSymbols(config.Players, config.Symbols)
+		if err != nil {
+			return nil, nil, err
+		}
+		multi, ok := engine.(MultiPlayer)
+		if !ok {
+			return nil, nil, NewPlayerCountUnsupportedError(config.Variant)
+		}
+		if err := multi.SetPlayers(players); err != nil {
+			return nil, nil, err
+		}
+		sessionSeats = players
+	} else if config.Symbols != nil {
+		return nil, nil, NewInvalidSymbolsError("only the extra seats of games with more than two players have their own symbols")
+	}
+	if config.Misere {
+		rules, ok := engine.(MisereRules)
+		if !ok {
+			return nil, nil, NewMisereUnsupportedError(config.Variant)
+		}
+		if err := rules.SetMisere(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if config.TimeControl > 0 {
+		timed, ok := engine.(Timed)
+		if !ok {
+			return nil, nil, NewTimeControlUnsupportedError(config.Variant)
+		}
+		timed.SetTimeControl(config.TimeControl)
+	}
+	if config.FirstMove != FirstMoveX {
+		mover, ok := engine.(FirstMover)
+		if !ok {
+			return nil, nil, NewFirstMoveUnsupportedError(config.Variant)
+		}
+		mover.SetFirstPlayer(firstPlayerOf(config.FirstMove, sessionSeats))
+	}
+	if config.StartPosition != "" {
+		if err := loadStartPosition(engine, config.StartPosition); err != nil {
+			return nil, nil, err
+		}
+	}
+	if config.Bot != "" {
+		if _, ok := engine.(searchable); !ok {
+			return nil, nil, NewBotUnsupportedError(config.Variant)
+		}
+	}
+	if config.Crowd && !crowdPlayable(engine) {
+		return nil, nil, NewCrowdUnsupportedError(config.Variant)
+	}
+	if config.VotingWindow < 0 {
+		return nil, nil, NewInvalidVotingWindowError(config.VotingWindow)
+	}
+	return engine, sessionSeats, nil
+}
+
+// checkMultiplayerConfig checks that a session with other than two seats only uses options that work with them
+// Bots, hints and clocks assume two players, and positions in compact notation only hold X and O
+func checkMultiplayerConfig(config *SessionConfig) error {
+	switch {
+	case config.Players < MinPlayers || config.Players > MaxPlayers:
+		return NewInvalidPlayerCountError(config.Players)
+	case config.Bot != "":
+		return NewMultiplayerUnsupportedError("bots")
+	case config.TimeControl > 0:
+		return NewMultiplayerUnsupportedError("time controls")
+	case config.Misere:
+		return NewMultiplayerUnsupportedError("misère rules")
+	case config.StartPosition != "":
+		return NewMultiplayerUnsupportedError("start positions")
+	case config.Crowd:
+		return NewMultiplayerUnsupportedError("crowd play")
+	}
+	return nil
+}
+
+// newEngine creates the game engine for a session configuration
+// A zero Board selects the variant's default board
+func newEngine(config *SessionConfig) (Engine, error) {
+	switch config.Variant {
+	case VariantTicTacToe:
+		board := config.Board
+		if board == (BoardConfig{}) {
+			board = DefaultBoardConfig
+		}
+		return NewTicTacToeWithBoard(board)
+	case VariantConnectFour:
+		board := config.Board
+		if board == (BoardConfig{}) {
+			board = ConnectFourBoardConfig
+		}
+		return NewConnectFourWithBoard(board)
+	case VariantUltimate:
+		if config.Board != (BoardConfig{}) {
+			return nil, NewInvalidBoardError(config.Board, "ultimate tic-tac-toe is always played on 9 local 3x3 boards")
+		}
+		return NewUltimateTicTacToe(), nil
+	case VariantQuantum:
+		if config.Board != (BoardConfig{}) {
+			return nil, NewInvalidBoardError(config.Board, "quantum tic-tac-toe is always played on a 3x3 board")
+		}
+		return NewQuantumTicTacToe(), nil
+	case VariantFog:
+		board := config.Board
+		if board == (BoardConfig{}) {
+			board = DefaultBoardConfig
+		}
+		return NewFogOfWar(board)
+	case VariantNotakto:
+		if config.Board != (BoardConfig{}) {
+			return nil, NewInvalidBoardError(config.Board, "Notakto is always played on 3x3 boards (choose how many with notakto-N)")
+		}
+		boards := config.Boards
+		if boards == 0 {
+			boards = DefaultNotaktoBoards
+		}
+		return NewNotakto(boards)
+	default:
+		return nil, NewUnknownVariantError(config.Variant)
+	}
+}
+
+// loadStartPosition sets up engine from a move list or, failing that, a position in compact notation
+func loadStartPosition(engine Engine, notation string) error {
+	if moves, err := ParseMoveList(notation); err == nil {
+		loader, ok := engine.(MoveLoader)
+		if !ok {
+			return NewNotationUnsupportedError(engine.GetState().Variant)
+		}
+		return loader.LoadMoves(moves)
+	}
+
+	loader, ok := engine.(PositionLoader)
+	if !ok {
+		return NewNotationUnsupportedError(engine.GetState().Variant)
+	}
+	state := engine.GetState()
+	board, turn, err := ParsePosition(notation, state.Rows, state.Cols)
+	if err != nil {
+		return err
+	}
+	return loader.LoadPosition(board, turn)
+}
+
 // GetSession retrieves a session by ID
 func (m *Manager) GetSession(id string) (*Session, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	session, exists := m.sessions[id]
+	session, exists := m.store.Get(id)
 	if !exists {
 		return nil, fmt.Errorf("session not found: %s", id)
 	}
@@ -96,22 +338,19 @@ func (m *Manager) DeleteSession(id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.sessions[id]; !exists {
+	if _, exists := m.store.Get(id); !exists {
 		return fmt.Errorf("session not found: %s", id)
 	}
 
-	delete(m.sessions, id)
-	return nil
+	return m.store.Delete(id)
 }
 
 // ListSessions returns a list of all active session IDs
 func (m *Manager) ListSessions() []string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	ids := make([]string, 0, len(m.sessions))
-	for id := range m.sessions {
-		ids = append(ids, id)
+	sessions := m.store.List()
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
 	}
 
 	return ids
@@ -119,51 +358,228 @@ func (m *Manager) ListSessions() []string {
 
 // GetSessionCount returns the number of active sessions
 func (m *Manager) GetSessionCount() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return len(m.sessions)
+	return len(m.store.List())
 }
 
 // JoinSession allows a player to join a session and returns a player token
-// First player gets X, second player gets O
-// Tic-tac-toe is always a 2-player game
+// Players take the first free seat: X, then O (then Δ and □, or the symbols set with WithSymbols, in sessions with more seats)
 func (s *Session) JoinSession() (PlayerToken, Player, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Check if session is full (tic-tac-toe is always 2 players)
-	if len(s.Players) >= 2 {
-		return "", "", fmt.Errorf("session is full (2 players already joined)")
+	if s.crowd != nil {
+		return "", "", fmt.Errorf("crowd-play session: vote for moves with vote-TEAM-ROW-COL instead of joining")
+	}
+	assignedPlayer, ok := s.freeSeat()
+	if !ok {
+		return "", "", fmt.Errorf("session is full (%d players already joined)", len(s.seats))
+	}
+
+	token := s.generateToken()
+	s.Players[token] = assignedPlayer
+	if s.host == "" {
+		s.host = token
+	}
+	s.touch()
+	s.publishRecord(&LogRecord{Event: Event{Type: EventPlayerJoined, Player: assignedPlayer}, TokenHash: hashToken(token)})
+
+	// Once the last seat is taken, start the game
+	if len(s.Players) == len(s.seats) {
+		s.Game.StartGame()
+	}
+
+	return token, assignedPlayer, nil
+}
+
+// JoinBot fills the next free seat with a server-side bot and returns the player it plays as
+// If the bot moves first, the caller should call PlayBotTurn once the game has started
+func (s *Session) JoinBot(difficulty Difficulty) (Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bot != nil {
+		return "", fmt.Errorf("session already has a bot (%s)", s.bot)
+	}
+	if _, ok := s.Game.(searchable); !ok {
+		return "", NewBotUnsupportedError(s.Game.GetState().Variant)
+	}
+	if len(s.seats) > 2 {
+		return "", NewMultiplayerUnsupportedError("bots")
+	}
+	if s.crowd != nil {
+		return "", fmt.Errorf("crowd-play session: create it with vs-ai to play the audience against a bot")
+	}
+
+	assignedPlayer, ok := s.freeSeat()
+	if !ok {
+		return "", fmt.Errorf("session is full (%d players already joined)", len(s.seats))
 	}
 
-	// Determine which player to assign
-	var assignedPlayer Player
-	if len(s.Players) == 0 {
-		assignedPlayer = PlayerX
-	} else {
-		assignedPlayer = PlayerO
+	s.seatBot(assignedPlayer, difficulty)
+	s.touch()
+	return assignedPlayer, nil
+}
+
+// seatBot seats a bot as player, starting the game once every seat is filled
+// Must be called with the lock held (or before the session is shared)
+func (s *Session) seatBot(player Player, difficulty Difficulty) {
+	s.bot = NewBot(player, difficulty)
+	token := s.generateToken()
+	s.Players[token] = player
+	s.publishRecord(&LogRecord{Event: Event{Type: EventPlayerJoined, Player: player}, TokenHash: hashToken(token), Bot: difficulty})
+
+	if len(s.Players) == len(s.seats) {
+		s.Game.StartGame()
+	}
+}
+
+// freeSeat returns the first seat nobody has taken yet
+// Must be called with the lock held
+func (s *Session) freeSeat() (Player, bool) {
+	taken := make(map[Player]bool, len(s.Players))
+	for _, player := range s.Players {
+		taken[player] = true
 	}
+	for _, seat := range s.seats {
+		if !taken[seat] {
+			return seat, true
+		}
+	}
+	return "", false
+}
 
-	// Generate a unique token for this player
+// generateToken generates a player token that is unique within the session
+// Must be called with the lock held
+func (s *Session) generateToken() PlayerToken {
 	tokenLength := 8
 	if s.config != nil {
 		tokenLength = s.config.PlayerTokenLength
 	}
-	token := GeneratePlayerToken(tokenLength)
 
 	// Ensure token uniqueness within the session (very unlikely, but check)
-	for _, exists := s.Players[token]; exists; {
+	token := GeneratePlayerToken(tokenLength)
+	for _, exists := s.Players[token]; exists; _, exists = s.Players[token] {
 		token = GeneratePlayerToken(tokenLength)
 	}
+	return token
+}
 
-	s.Players[token] = assignedPlayer
+// GetBot returns the session's bot, or nil if every seat is held by a human
+func (s *Session) GetBot() *Bot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bot
+}
 
-	// If this is the second player joining, start the game
-	if len(s.Players) == 2 {
-		s.Game.StartGame()
+// PlayBotTurn lets the bot move if it is the bot's turn in a game in progress
+// Returns the bot's move, or nil if it was not the bot's turn
+func (s *Session) PlayBotTurn() (*Position, error) {
+	bot := s.GetBot()
+	if bot == nil {
+		return nil, nil
 	}
 
-	return token, assignedPlayer, nil
+	state := s.Game.GetState()
+	if state.Status != StatusPlaying || state.Turn != bot.Player {
+		return nil, nil
+	}
+
+	move, err := bot.ChooseMove(s.Game)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Game.MakeMove(move.Row, move.Col, bot.Player); err != nil {
+		return nil, err
+	}
+	return &move, nil
+}
+
+// AnswerDrawOffer lets the session's bot answer a pending draw offer
+// The bot accepts unless the solver shows it can still win (or the position is too large to solve)
+// Returns whether the offer was accepted; false if the session has no bot
+func (s *Session) AnswerDrawOffer() (bool, error) {
+	bot := s.GetBot()
+	if bot == nil {
+		return false, nil
+	}
+
+	analysis, err := s.solver.Analyze(s.Game)
+	if err != nil {
+		return false, err
+	}
+	if analysis.Value == nil {
+		return false, nil
+	}
+	botWins := analysis.Value.Result == ResultWin
+	if analysis.Turn != bot.Player {
+		botWins = analysis.Value.Result == ResultLoss
+	}
+	if botWins {
+		return false, nil
+	}
+
+	if err := s.Game.AcceptDraw(bot.Player); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// enforceTurnLimit acts for the player to move if they are human and their turn has lasted longer than limit
+// Returns true if the server acted for them
+func (s *Session) enforceTurnLimit(limit time.Duration, action InactivityAction) bool {
+	state := s.Game.GetState()
+	if state.Status != StatusPlaying || time.Since(state.TurnStarted) <= limit {
+		return false
+	}
+	if bot := s.GetBot(); bot != nil && bot.Player == state.Turn {
+		return false
+	}
+
+	player := state.Turn
+	reason := fmt.Sprintf("%s was inactive for more than %s", player, limit)
+	// A forfeit would not say who wins a game with more than two players, so they get a random move instead
+	multiplayer := len(state.Players) > 2
+	if action == InactivityForfeit && !multiplayer {
+		return s.Game.Forfeit(player, reason) == nil
+	}
+
+	g, ok := s.Game.(searchable)
+	if !ok {
+		if multiplayer {
+			return false
+		}
+		return s.Game.Forfeit(player, reason) == nil
+	}
+	moves := g.snapshot().legalMoves()
+	if len(moves) == 0 {
+		return false
+	}
+	move := moves[rand.Intn(len(moves))]
+	if err := s.Game.MakeMove(move.Row, move.Col, player); err != nil {
+		// The player moved in the meantime
+		return false
+	}
+	if annotated, ok := s.Game.(moveAnnotator); ok {
+		annotated.annotateLastMove(len(state.Moves)+1, reason)
+	}
+	s.PlayBotTurn()
+	return true
+}
+
+// Hint returns the best move for player in the current position, using the manager's shared solver
+func (s *Session) Hint(player Player) (*Hint, error) {
+	if s.SeatCount() > 2 {
+		return nil, NewMultiplayerUnsupportedError("hints")
+	}
+	return s.solver.Hint(s.Game, player)
+}
+
+// Analyze evaluates the current position and the last move, using the manager's shared solver
+func (s *Session) Analyze() (*Analysis, error) {
+	if s.SeatCount() > 2 {
+		return nil, NewMultiplayerUnsupportedError("analysis")
+	}
+	return s.solver.Analyze(s.Game)
 }
 
 // GetPlayer returns the Player (X or O) associated with a token
@@ -171,12 +587,28 @@ func (s *Session) GetPlayer(token PlayerToken) (Player, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	player, exists := s.Players[token]
+	key, exists := s.playerKey(token)
 	if !exists {
 		return "", fmt.Errorf("invalid player token: %s", token)
 	}
 
-	return player, nil
+	return s.Players[key], nil
+}
+
+// playerKey returns the key Players holds token's player under: the token itself, or its hash for
+// players whose join was replayed from the event log, which records hashes instead of tokens
+// Must be called with the lock held
+func (s *Session) playerKey(token PlayerToken) (PlayerToken, bool) {
+	// Hashes stand in for tokens, but are not tokens themselves
+	if strings.HasPrefix(string(token), tokenHashPrefix) {
+		return "", false
+	}
+	if _, ok := s.Players[token]; ok {
+		return token, true
+	}
+	hashed := hashToken(token)
+	_, ok := s.Players[hashed]
+	return hashed, ok
 }
 
 // GetPlayerInfo returns information about all players in the session
@@ -202,15 +634,17 @@ func (s *Session) GetPlayerCount() int {
 	return len(s.Players)
 }
 
-// CleanupOldSessions removes sessions older than the specified duration
-func (m *Manager) CleanupOldSessions(maxAge time.Duration) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// SeatCount returns the number of seats in the session; the game starts once every seat is taken
+func (s *Session) SeatCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.seats)
+}
 
-	now := time.Now()
-	for id, session := range m.sessions {
-		if now.Sub(session.CreatedAt) > maxAge {
-			delete(m.sessions, id)
-		}
+// EnforceTurnLimit acts for every human player whose turn has lasted longer than limit,
+// either forfeiting their game or playing a random legal move for them
+func (m *Manager) EnforceTurnLimit(limit time.Duration, action InactivityAction) {
+	for _, session := range m.store.List() {
+		session.enforceTurnLimit(limit, action)
 	}
 }
@@ -0,0 +1,255 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SessionSnapshot holds everything needed to restore a session after a restart
+// Open crowd-play voting rounds are not kept: voting on the move starts over
+type SessionSnapshot struct {
+	ID string `json:"id"`
+	// Settings are the options the session was created with, used to rebuild its engine
+	Settings   SessionConfig          `json:"settings"`
+	CreatedAt  time.Time              `json:"created_at"`
+	LastActive time.Time              `json:"last_active"`
+	Players    map[PlayerToken]Player `json:"players"`
+	Host       PlayerToken            `json:"host,omitempty"`
+	Bot        *Bot                   `json:"bot,omitempty"`
+	FirstMove  FirstMove              `json:"first_move"`
+	// NextFirstPlayer is who moves first in the next game, "" for X
+	NextFirstPlayer Player `json:"next_first_player,omitempty"`
+	// Games, Wins and Draws are the results of the series' earlier games
+	Games int                 `json:"games,omitempty"`
+	Wins  map[PlayerToken]int `json:"wins,omitempty"`
+	Draws int                 `json:"draws,omitempty"`
+	// VotingWindow is the voting window of a crowd-play session
+	VotingWindow time.Duration `json:"voting_window,omitempty"`
+	State        *GameState    `json:"state"`
+	LastGame     *GameState    `json:"last_game,omitempty"`
+	// LogSeq is the last event log record of the session the snapshot includes, see LogStore
+	LogSeq uint64 `json:"log_seq,omitempty"`
+
+	pending []LogRecord // the records logged after the snapshot, which RestoreSessions replays
+}
+
+// hashTokens replaces the players' tokens with their hashes, for logging the snapshot, see hashToken
+func (snapshot *SessionSnapshot) hashTokens() {
+	players := make(map[PlayerToken]Player, len(snapshot.Players))
+	for token, player := range snapshot.Players {
+		players[hashToken(token)] = player
+	}
+	wins := make(map[PlayerToken]int, len(snapshot.Wins))
+	for token, count := range snapshot.Wins {
+		wins[hashToken(token)] = count
+	}
+	snapshot.Players, snapshot.Wins = players, wins
+	if snapshot.Host != "" {
+		snapshot.Host = hashToken(snapshot.Host)
+	}
+}
+
+// restorable is implemented by engines whose state can be saved and restored, which every engine is through baseEngine
+type restorable interface {
+	// saveState returns a copy of the state and the player who moves first in the next game
+	saveState() (*GameState, Player)
+	// restoreState replaces the state with one saved by saveState
+	restoreState(state *GameState, firstPlayer Player)
+}
+
+// saveState returns a copy of the state, with the running clock settled, and the player who moves first in the next game
+func (b *baseEngine) saveState() (*GameState, Player) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tickClock()
+	return b.state.Clone(), b.firstPlayer
+}
+
+// restoreState replaces the state with one saved by saveState
+// The turn of a game in play starts again from now, so the time the server was down is charged to no one,
+// on the clock or by the inactivity limit
+func (b *baseEngine) restoreState(state *GameState, firstPlayer Player) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = state
+	b.firstPlayer = firstPlayer
+	if state.Status == StatusPlaying {
+		now := time.Now()
+		state.TurnStarted = now
+		if state.Clock != nil {
+			state.Clock.TurnStarted = now
+		}
+	}
+}
+
+// Snapshot returns the session as it is now, for saving it
+func (s *Session) Snapshot() *SessionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := &SessionSnapshot{
+		ID:         s.ID,
+		Settings:   s.settings,
+		CreatedAt:  s.CreatedAt,
+		LastActive: s.LastActive(),
+		Players:    make(map[PlayerToken]Player, len(s.Players)),
+		Host:       s.host,
+		FirstMove:  s.firstMove,
+		Games:      s.record.games,
+		Wins:       make(map[PlayerToken]int, len(s.record.wins)),
+		Draws:      s.record.draws,
+		LastGame:   s.lastGame,
+	}
+	for token, player := range s.Players {
+		snapshot.Players[token] = player
+	}
+	for token, wins := range s.record.wins {
+		snapshot.Wins[token] = wins
+	}
+	if s.bot != nil {
+		bot := *s.bot
+		snapshot.Bot = &bot
+	}
+	if s.crowd != nil {
+		snapshot.VotingWindow = s.crowd.window
+	}
+	g, ok := s.Game.(restorable)
+	if !ok {
+		snapshot.State = s.Game.GetState()
+		return snapshot
+	}
+	// Moves are recorded under the engine's lock, so the state matches the last record unless one was
+	// written while it was being saved
+	for {
+		seq := s.logSeq.Load()
+		snapshot.State, snapshot.NextFirstPlayer = g.saveState()
+		if s.logSeq.Load() == seq {
+			snapshot.LogSeq = seq
+			return snapshot
+		}
+	}
+}
+
+// restoreSession rebuilds a session from a snapshot, with a fresh engine set up from the session's settings
+// and then given the saved state
+func (m *Manager) restoreSession(snapshot *SessionSnapshot) (*Session, error) {
+	if snapshot.State == nil {
+		return nil, fmt.Errorf("no game state saved")
+	}
+	settings := snapshot.Settings
+	engine, sessionSeats, err := newSessionEngine(&settings)
+	if err != nil {
+		return nil, err
+	}
+	g, ok := engine.(restorable)
+	if !ok {
+		return nil, fmt.Errorf("%s games cannot be restored", settings.Variant)
+	}
+	fresh := engine.GetState()
+	if saved := snapshot.State; saved.Variant != fresh.Variant || saved.Rows != fresh.Rows || saved.Cols != fresh.Cols {
+		return nil, fmt.Errorf("saved %s %dx%d game does not match the session's settings", saved.Variant, saved.Rows, saved.Cols)
+	}
+	g.restoreState(snapshot.State, snapshot.NextFirstPlayer)
+
+	session := &Session{
+		ID:        snapshot.ID,
+		Game:      engine,
+		Players:   make(map[PlayerToken]Player, len(snapshot.Players)),
+		CreatedAt: snapshot.CreatedAt,
+		settings:  snapshot.Settings,
+		config:    m.config,
+		solver:    m.solver,
+		record: seriesRecord{
+			bestOf: settings.BestOf,
+			games:  snapshot.Games,
+			wins:   make(map[PlayerToken]int, len(snapshot.Wins)),
+			draws:  snapshot.Draws,
+		},
+		lastGame:  snapshot.LastGame,
+		events:    m.events,
+		recorder:  m.recorder,
+		firstMove: snapshot.FirstMove,
+		host:      snapshot.Host,
+		seats:     sessionSeats,
+	}
+	for token, player := range snapshot.Players {
+		session.Players[token] = player
+	}
+	for token, wins := range snapshot.Wins {
+		session.record.wins[token] = wins
+	}
+	if snapshot.Bot != nil {
+		session.bot = NewBot(snapshot.Bot.Player, snapshot.Bot.Difficulty)
+	}
+	if settings.Crowd {
+		session.crowd = &votingRound{window: snapshot.VotingWindow}
+	}
+	session.lastActive.Store(snapshot.LastActive.UnixNano())
+	session.logSeq.Store(snapshot.LogSeq)
+	if observable, ok := engine.(Observable); ok {
+		observable.SetObserver(session.observe)
+	}
+	return session, nil
+}
+
+// RestoreSessions brings back the sessions the store saved in an earlier run and returns how many it restored
+// Sessions that cannot be restored are skipped and reported in the error
+func (m *Manager) RestoreSessions() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshots, err := m.store.Load()
+	errs := []error{err}
+	restored := 0
+	for _, snapshot := range snapshots {
+		session, err := m.restoreSession(snapshot)
+		if err == nil {
+			// A session the log cannot be fully replayed on is kept as far as it could be
+			if replayErr := session.replayLog(snapshot.pending); replayErr != nil {
+				errs = append(errs, fmt.Errorf("failed to replay the event log on session %s: %w", snapshot.ID, replayErr))
+			}
+			err = m.store.Put(session)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore session %s: %w", snapshot.ID, err))
+			continue
+		}
+		restored++
+	}
+	return restored, errors.Join(errs...)
+}
+
+// SaveSession saves the current state of a session that just changed, for stores that persist it,
+// so a crash right after the change does not lose it
+func (m *Manager) SaveSession(session *Session) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// A session deleted in the meantime must not be saved again
+	if _, ok := m.store.Get(session.ID); !ok {
+		return nil
+	}
+	return m.store.Put(session)
+}
+
+// SaveSessions saves the current state of every session, for stores that persist them
+// Sessions also change without anyone asking (clocks, bots, inactivity), so this should run periodically
+// and before the server exits. Once every session is saved, stores that keep an event log compact it.
+func (m *Manager) SaveSessions() error {
+	// Holding the manager's lock keeps a session from being saved again just after it was deleted,
+	// and sessions from being added while the log is compacted
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var errs []error
+	for _, session := range m.store.List() {
+		if err := m.store.Put(session); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c, ok := m.store.(compactor); ok && len(errs) == 0 {
+		errs = append(errs, c.compact())
+	}
+	return errors.Join(errs...)
+}
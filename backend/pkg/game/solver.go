@@ -0,0 +1,465 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Solver limits
+const (
+	// maxSolveTime bounds a solve so a hint is always answered within one DNS query
+	maxSolveTime = time.Second
+	// maxSolverCacheEntries caps the solved position cache; it is cleared when full
+	maxSolverCacheEntries = 1 << 20
+	// maxSolveEmptyCells is the most empty cells a position may have for the solver to try it;
+	// larger positions could never be solved in time, so they are reported unsolved without searching
+	maxSolveEmptyCells = 16
+)
+
+// Result is the game-theoretic result of a position for the player to move
+type Result string
+
+const (
+	ResultWin  Result = "win"
+	ResultDraw Result = "draw"
+	ResultLoss Result = "loss"
+	// ResultUnknown means the position was too large to solve in time
+	ResultUnknown Result = "unknown"
+)
+
+// Outcome is the result of a position when both players play perfectly
+type Outcome struct {
+	Result Result `json:"result"`
+	// Plies is the number of moves, counting both players, until the game is decided (0 for draws and unknown results)
+	Plies int `json:"plies,omitempty"`
+}
+
+// String returns a human-readable outcome, e.g. "win in 3 moves"
+func (o Outcome) String() string {
+	switch o.Result {
+	case ResultWin, ResultLoss:
+		if o.Plies == 1 {
+			return fmt.Sprintf("%s in 1 move", o.Result)
+		}
+		return fmt.Sprintf("%s in %d moves", o.Result, o.Plies)
+	default:
+		return string(o.Result)
+	}
+}
+
+// rank orders results from the point of view of the player they belong to
+func (o Outcome) rank() int {
+	switch o.Result {
+	case ResultWin:
+		return 1
+	case ResultLoss:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// Hint is the best move for a player and the outcome it leads to
+type Hint struct {
+	Player  Player   `json:"player"`
+	Move    Position `json:"move"`
+	Outcome Outcome  `json:"outcome"`
+}
+
+// Solver finds perfect-play results by exhaustive alpha-beta search
+// Results are cached by canonical position (board symmetries collapsed), so the cache
+// is shared between sessions and repeated queries on the same position are cheap.
+// The scored moves of every position solved as a whole are kept as well, so analyzing a move
+// reuses the result of analyzing the position before it.
+// Searches run concurrently; the lock only guards the caches.
+type Solver struct {
+	cache  map[string]solverEntry
+	solved map[string][]scoredMove // keyed by exact position, as the moves are not mapped by symmetry
+	mu     sync.RWMutex
+}
+
+// bound tells whether a cached score is exact or only a bound on the real score
+type bound int
+
+const (
+	boundExact bound = iota
+	boundLower
+	boundUpper
+)
+
+// solverEntry is a cached search result; won and lost scores are stored relative to the position
+type solverEntry struct {
+	score int
+	bound bound
+}
+
+// scoredMove is a legal move and its exact score for the player making it
+type scoredMove struct {
+	move  Position
+	score int
+}
+
+// NewSolver creates a solver with empty caches
+func NewSolver() *Solver {
+	return &Solver{
+		cache:  make(map[string]solverEntry),
+		solved: make(map[string][]scoredMove),
+	}
+}
+
+// Hint returns the best move for player in the engine's current position
+// Positions too large to solve in time fall back to the hard bot's move with an unknown outcome
+func (s *Solver) Hint(engine Engine, player Player) (*Hint, error) {
+	g, ok := engine.(searchable)
+	if !ok {
+		return nil, NewAnalysisUnsupportedError(engine.GetState().Variant)
+	}
+
+	sim := g.snapshot()
+	state := sim.rawState()
+	if state.Status != StatusPlaying {
+		return nil, NewGameOverError(state.Status)
+	}
+	if state.Turn != player {
+		return nil, NewWrongTurnError(player, state.Turn)
+	}
+
+	moves, solved := s.solve(sim, player, time.Now().Add(maxSolveTime))
+	if !solved {
+		move := NewBot(player, DifficultyHard).searchDeepening(sim)
+		return &Hint{Player: player, Move: move, Outcome: Outcome{Result: ResultUnknown}}, nil
+	}
+
+	best := moves[0]
+	return &Hint{Player: player, Move: best.move, Outcome: outcomeOf(best.score)}, nil
+}
+
+// Analysis is the perfect-play evaluation of a position
+type Analysis struct {
+	Turn   Player `json:"turn"`
+	Status Status `json:"status"`
+	// Solved is false when the position was too large to solve in time
+	Solved bool `json:"solved"`
+	// Value is the result for the player to move, nil once the game is over
+	Value *Outcome `json:"value,omitempty"`
+	// Moves lists every legal move with the result it leads to for the player to move, best first
+	Moves    []MoveAnalysis    `json:"moves"`
+	LastMove *LastMoveAnalysis `json:"last_move,omitempty"`
+}
+
+// MoveAnalysis is a legal move and the result it leads to for the player making it
+type MoveAnalysis struct {
+	Row     int     `json:"row"`
+	Col     int     `json:"col"`
+	Outcome Outcome `json:"outcome"`
+}
+
+// LastMoveAnalysis compares the last move played with the best move that was available
+type LastMoveAnalysis struct {
+	Move
+	// Outcome is the result the move leads to for the player who made it
+	Outcome Outcome `json:"outcome"`
+	// BestOutcome is the result the best move would have led to
+	BestOutcome Outcome `json:"best_outcome"`
+	// Blunder is true when the move gave away a win or a draw
+	Blunder bool `json:"blunder"`
+}
+
+// rewindable engines can rebuild their position without their latest moves (see baseEngine.rollback)
+// or from any list of moves (see baseEngine.rebuild)
+type rewindable interface {
+	replayer
+	rollback(r replayer, count int) error
+	rebuild(r replayer, moves []Move) error
+}
+
+// Analyze evaluates the engine's current position: its value, every legal move, and whether
+// the last move played was a blunder. Positions too large to solve in time are reported unsolved.
+func (s *Solver) Analyze(engine Engine) (*Analysis, error) {
+	g, ok := engine.(searchable)
+	if !ok {
+		return nil, NewAnalysisUnsupportedError(engine.GetState().Variant)
+	}
+
+	deadline := time.Now().Add(maxSolveTime)
+	sim := g.snapshot()
+	state := sim.rawState()
+	analysis := &Analysis{
+		Turn:   state.Turn,
+		Status: state.Status,
+		Solved: true,
+		Moves:  []MoveAnalysis{},
+	}
+
+	if state.Status == StatusPlaying {
+		moves, solved := s.solve(sim, state.Turn, deadline)
+		if !solved {
+			analysis.Solved = false
+			return analysis, nil
+		}
+		for _, m := range moves {
+			analysis.Moves = append(analysis.Moves, MoveAnalysis{Row: m.move.Row, Col: m.move.Col, Outcome: outcomeOf(m.score)})
+		}
+		if len(moves) > 0 {
+			value := outcomeOf(moves[0].score)
+			analysis.Value = &value
+		}
+	}
+
+	if len(state.Moves) == 0 {
+		return analysis, nil
+	}
+	last, solved := s.analyzeLastMove(sim, deadline)
+	if !solved {
+		analysis.Solved = false
+		return analysis, nil
+	}
+	analysis.LastMove = last
+	return analysis, nil
+}
+
+// analyzeLastMove solves the position before the last move and compares the move played with the best one
+// That position is usually solved already, when it was analyzed before the move was made
+// Returns false if it is too large to solve or could not be solved before the deadline
+func (s *Solver) analyzeLastMove(g searchable, deadline time.Time) (*LastMoveAnalysis, bool) {
+	state := g.rawState()
+	last := state.Moves[len(state.Moves)-1]
+	if countEmpty(state.Board) >= maxSolveEmptyCells {
+		// The position before the move has one more empty cell, so it cannot be solved either
+		return nil, false
+	}
+
+	previous := g.snapshot()
+	r, ok := previous.(rewindable)
+	if !ok || r.rollback(r, 1) != nil {
+		return nil, false
+	}
+
+	moves, solved := s.solve(previous, last.Player, deadline)
+	if !solved || len(moves) == 0 {
+		return nil, false
+	}
+
+	analysis := &LastMoveAnalysis{Move: last, BestOutcome: outcomeOf(moves[0].score)}
+	for _, m := range moves {
+		if m.move.Row == last.Row && m.move.Col == last.Col {
+			analysis.Outcome = outcomeOf(m.score)
+		}
+	}
+	analysis.Blunder = analysis.Outcome.rank() < analysis.BestOutcome.rank()
+	return analysis, true
+}
+
+// solvable reports whether the position is small enough for the solver to try
+func solvable(g searchable) bool {
+	return countEmpty(g.rawState().Board) <= maxSolveEmptyCells
+}
+
+// solve scores every legal move for player exactly, best first
+// Returns false if the position is too large to solve or could not be solved before the deadline
+func (s *Solver) solve(g searchable, player Player, deadline time.Time) ([]scoredMove, bool) {
+	if !solvable(g) {
+		return nil, false
+	}
+
+	key := positionKey(g.rawState(), player, []symmetry{identity})
+	s.mu.Lock()
+	if moves, ok := s.solved[key]; ok {
+		s.mu.Unlock()
+		return moves, true
+	}
+	if len(s.cache) > maxSolverCacheEntries {
+		s.cache = make(map[string]solverEntry)
+		s.solved = make(map[string][]scoredMove)
+	}
+	s.mu.Unlock()
+
+	var moves []scoredMove
+	for _, move := range orderFromCenter(g.legalMoves(), g.rawState()) {
+		child := g.snapshot()
+		if err := child.MakeMove(move.Row, move.Col, player); err != nil {
+			continue
+		}
+		score, ok := s.negamax(child, 1, -winScore-1, winScore+1, otherPlayer(player), deadline)
+		if !ok {
+			return nil, false
+		}
+		moves = append(moves, scoredMove{move: move, score: -score})
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].score > moves[j].score
+	})
+	s.mu.Lock()
+	s.solved[key] = moves
+	s.mu.Unlock()
+	return moves, true
+}
+
+// negamax scores the position exactly from the point of view of player (the player to move),
+// consulting and filling the cache. Returns false if the deadline passed.
+func (s *Solver) negamax(g searchable, ply, alpha, beta int, player Player, deadline time.Time) (int, bool) {
+	state := g.rawState()
+	if state.Status != StatusPlaying {
+		return evaluate(state, player, ply), true
+	}
+
+	key := positionKey(state, player, boardSymmetries(state))
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	s.mu.RUnlock()
+	if ok {
+		score := fromCache(cached.score, ply)
+		switch cached.bound {
+		case boundExact:
+			return score, true
+		case boundLower:
+			alpha = max(alpha, score)
+		case boundUpper:
+			beta = min(beta, score)
+		}
+		if alpha >= beta {
+			return score, true
+		}
+	}
+
+	if time.Now().After(deadline) {
+		return 0, false
+	}
+
+	originalAlpha := alpha
+	best := -winScore - 1
+	for _, move := range orderFromCenter(g.legalMoves(), state) {
+		child := g.snapshot()
+		if err := child.MakeMove(move.Row, move.Col, player); err != nil {
+			continue
+		}
+		score, ok := s.negamax(child, ply+1, -beta, -alpha, otherPlayer(player), deadline)
+		if !ok {
+			return 0, false
+		}
+		score = -score
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	entry := solverEntry{score: toCache(best, ply), bound: boundExact}
+	if best <= originalAlpha {
+		entry.bound = boundUpper
+	} else if best >= beta {
+		entry.bound = boundLower
+	}
+	s.mu.Lock()
+	s.cache[key] = entry
+	s.mu.Unlock()
+	return best, true
+}
+
+// toCache converts a won or lost score from distance-to-root to distance-to-position
+func toCache(score, ply int) int {
+	switch {
+	case score > winScore/2:
+		return score + ply
+	case score < -winScore/2:
+		return score - ply
+	default:
+		return score
+	}
+}
+
+// fromCache converts a cached won or lost score back to distance-to-root
+func fromCache(score, ply int) int {
+	switch {
+	case score > winScore/2:
+		return score - ply
+	case score < -winScore/2:
+		return score + ply
+	default:
+		return score
+	}
+}
+
+// outcomeOf converts an exact score at the root to an outcome
+func outcomeOf(score int) Outcome {
+	switch {
+	case score > winScore/2:
+		return Outcome{Result: ResultWin, Plies: winScore - score}
+	case score < -winScore/2:
+		return Outcome{Result: ResultLoss, Plies: winScore + score}
+	default:
+		return Outcome{Result: ResultDraw}
+	}
+}
+
+// symmetry maps a cell to its image under a board symmetry
+type symmetry func(row, col, rows, cols int) (int, int)
+
+var (
+	identity   symmetry = func(row, col, rows, cols int) (int, int) { return row, col }
+	mirrorCols symmetry = func(row, col, rows, cols int) (int, int) { return row, cols - 1 - col }
+	mirrorRows symmetry = func(row, col, rows, cols int) (int, int) { return rows - 1 - row, col }
+	rotate180  symmetry = func(row, col, rows, cols int) (int, int) { return rows - 1 - row, cols - 1 - col }
+	transpose  symmetry = func(row, col, rows, cols int) (int, int) { return col, row }
+	antiDiag   symmetry = func(row, col, rows, cols int) (int, int) { return cols - 1 - col, rows - 1 - row }
+	rotate90   symmetry = func(row, col, rows, cols int) (int, int) { return col, rows - 1 - row }
+	rotate270  symmetry = func(row, col, rows, cols int) (int, int) { return cols - 1 - col, row }
+)
+
+// boardSymmetries returns the symmetries that preserve the rules of the state's variant:
+// all 8 on square boards, 4 on rectangular ones, only the mirror image under gravity,
+// and none for ultimate tic-tac-toe, where the forced board would have to be mapped too
+func boardSymmetries(state *GameState) []symmetry {
+	switch {
+	case state.Ultimate != nil:
+		return []symmetry{identity}
+	case state.Variant == VariantConnectFour:
+		return []symmetry{identity, mirrorCols}
+	case state.Rows == state.Cols:
+		return []symmetry{identity, mirrorCols, mirrorRows, rotate180, transpose, antiDiag, rotate90, rotate270}
+	default:
+		return []symmetry{identity, mirrorCols, mirrorRows, rotate180}
+	}
+}
+
+// positionKey identifies the position up to the given symmetries: the rules, the player to move
+// and the smallest encoding of the board over its images under them
+func positionKey(state *GameState, player Player, symmetries []symmetry) string {
+	var canonical []byte
+	cells := make([]byte, state.Rows*state.Cols)
+	for _, sym := range symmetries {
+		for row := range state.Board {
+			for col, cell := range state.Board[row] {
+				r, c := sym(row, col, state.Rows, state.Cols)
+				cells[r*state.Cols+c] = cellByte(cell)
+			}
+		}
+		if canonical == nil || string(cells) < string(canonical) {
+			canonical = append(canonical[:0], cells...)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s/%dx%d-%d/%s/", state.Variant, state.Rows, state.Cols, state.WinLength, player))
+	if state.Ultimate != nil {
+		sb.WriteString(fmt.Sprintf("%d/", state.Ultimate.ForcedBoard))
+	}
+	if state.Misere {
+		sb.WriteString("misere/")
+	}
+	sb.Write(canonical)
+	return sb.String()
+}
+
+// cellByte encodes a cell for position keys
+func cellByte(cell Player) byte {
+	if cell == "" {
+		return '_'
+	}
+	return cell[0]
+}
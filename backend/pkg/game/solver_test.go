@@ -0,0 +1,177 @@
+package game
+
+import "testing"
+
+// newPlayedGame starts a 3x3 game and plays moves alternately for X and O, starting with X
+func newPlayedGame(t *testing.T, moves ...Position) *TicTacToe {
+	t.Helper()
+	g := NewTicTacToe()
+	g.StartGame()
+	for i, move := range moves {
+		player := PlayerX
+		if i%2 == 1 {
+			player = PlayerO
+		}
+		if err := g.MakeMove(move.Row, move.Col, player); err != nil {
+			t.Fatalf("move %d: %v", i+1, err)
+		}
+	}
+	return g
+}
+
+func TestSolverHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		moves  []Position
+		player Player
+		want   Position
+		result Outcome
+	}{
+		{
+			name:   "win in one",
+			moves:  []Position{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+			player: PlayerX,
+			want:   Position{0, 2},
+			result: Outcome{Result: ResultWin, Plies: 1},
+		},
+		{
+			name:   "block the only threat",
+			moves:  []Position{{0, 0}, {1, 1}, {0, 1}},
+			player: PlayerO,
+			want:   Position{0, 2},
+			result: Outcome{Result: ResultDraw},
+		},
+		{
+			name:   "fork wins in three",
+			moves:  []Position{{0, 0}, {0, 1}, {1, 1}, {2, 2}},
+			player: PlayerX,
+			result: Outcome{Result: ResultWin, Plies: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint, err := NewSolver().Hint(newPlayedGame(t, tt.moves...), tt.player)
+			if err != nil {
+				t.Fatalf("Hint: %v", err)
+			}
+			if hint.Outcome != tt.result {
+				t.Errorf("outcome = %s, want %s", hint.Outcome, tt.result)
+			}
+			if tt.want != (Position{}) && hint.Move != tt.want {
+				t.Errorf("move = %v, want %v", hint.Move, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolverHintWrongTurn(t *testing.T) {
+	if _, err := NewSolver().Hint(newPlayedGame(t), PlayerO); err == nil {
+		t.Error("hint for the player not to move succeeded")
+	}
+}
+
+func TestSolverAnalyzeEmptyBoard(t *testing.T) {
+	analysis, err := NewSolver().Analyze(newPlayedGame(t))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if !analysis.Solved || analysis.Value == nil || analysis.Value.Result != ResultDraw {
+		t.Fatalf("empty board analyzed as %+v, want a solved draw", analysis)
+	}
+	if len(analysis.Moves) != 9 {
+		t.Errorf("analyzed %d moves, want 9", len(analysis.Moves))
+	}
+	for _, move := range analysis.Moves {
+		if move.Outcome.Result != ResultDraw {
+			t.Errorf("move %d,%d leads to %s, want a draw", move.Row, move.Col, move.Outcome)
+		}
+	}
+	if analysis.LastMove != nil {
+		t.Errorf("last move analyzed before any move: %+v", analysis.LastMove)
+	}
+}
+
+func TestSolverAnalyzeLastMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		moves   []Position
+		blunder bool
+		outcome Result
+	}{
+		{"corner reply to center", []Position{{1, 1}, {0, 0}}, false, ResultDraw},
+		{"edge reply to center", []Position{{1, 1}, {0, 1}}, true, ResultLoss},
+	}
+	solver := NewSolver()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := solver.Analyze(newPlayedGame(t, tt.moves...))
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			last := analysis.LastMove
+			if !analysis.Solved || last == nil {
+				t.Fatalf("last move not analyzed: %+v", analysis)
+			}
+			if last.Blunder != tt.blunder || last.Outcome.Result != tt.outcome {
+				t.Errorf("last move: blunder %t, %s; want blunder %t, %s", last.Blunder, last.Outcome, tt.blunder, tt.outcome)
+			}
+			if last.BestOutcome.Result != ResultDraw {
+				t.Errorf("best outcome = %s, want draw", last.BestOutcome)
+			}
+		})
+	}
+}
+
+func TestSolverLargeBoardUnsolved(t *testing.T) {
+	g, err := NewTicTacToeWithBoard(BoardConfig{Rows: 15, Cols: 15, WinLength: 5})
+	if err != nil {
+		t.Fatalf("NewTicTacToeWithBoard: %v", err)
+	}
+	g.StartGame()
+	if err := g.MakeMove(7, 7, PlayerX); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+
+	solver := NewSolver()
+	analysis, err := solver.Analyze(g)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if analysis.Solved || analysis.Value != nil {
+		t.Errorf("15x15 board analyzed as %+v, want unsolved", analysis)
+	}
+
+	hint, err := solver.Hint(g, PlayerO)
+	if err != nil {
+		t.Fatalf("Hint: %v", err)
+	}
+	if hint.Outcome.Result != ResultUnknown {
+		t.Errorf("outcome = %s, want unknown", hint.Outcome)
+	}
+	if cell := g.GetState().Board[hint.Move.Row][hint.Move.Col]; cell != "" {
+		t.Errorf("hinted move %v is taken by %s", hint.Move, cell)
+	}
+}
+
+func TestSolverScores(t *testing.T) {
+	// Won and lost scores survive the cache at any depth of a 15x15 search, and never pass for heuristics
+	for ply := 0; ply <= 225; ply++ {
+		for _, score := range []int{winScore - ply, -winScore + ply} {
+			if got := fromCache(toCache(score, ply), ply); got != score {
+				t.Errorf("score %d at ply %d comes back from the cache as %d", score, ply, got)
+			}
+			if score > -winScore/2 && score < winScore/2 {
+				t.Errorf("score %d at ply %d is not a won or lost score", score, ply)
+			}
+		}
+		if outcome := outcomeOf(winScore - ply); outcome != (Outcome{Result: ResultWin, Plies: ply}) {
+			t.Errorf("outcomeOf(winScore-%d) = %s", ply, outcome)
+		}
+		if outcome := outcomeOf(-winScore + ply); outcome != (Outcome{Result: ResultLoss, Plies: ply}) {
+			t.Errorf("outcomeOf(-winScore+%d) = %s", ply, outcome)
+		}
+	}
+	if outcome := outcomeOf(maxHeuristic); outcome.Result != ResultDraw {
+		t.Errorf("heuristic score read as %s", outcome)
+	}
+}
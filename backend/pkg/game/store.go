@@ -0,0 +1,204 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SessionStore keeps the sessions of a manager
+// Sessions change in place as they are played, so stores that persist them save a session's current
+// state each time it is put again, see Manager.SaveSessions
+type SessionStore interface {
+	// Get returns the session with the given ID
+	Get(id string) (*Session, bool)
+
+	// Put adds a new session, or saves the current state of one already in the store
+	Put(session *Session) error
+
+	// Delete removes the session with the given ID, if any
+	Delete(id string) error
+
+	// List returns every session in the store
+	List() []*Session
+
+	// Load returns the sessions saved by an earlier run, see Manager.RestoreSessions
+	Load() ([]*SessionSnapshot, error)
+}
+
+// WithStore keeps the manager's sessions in store instead of in memory only
+func WithStore(store SessionStore) ManagerOption {
+	return func(c *ManagerConfig) {
+		c.Store = store
+	}
+}
+
+// MemoryStore keeps sessions in memory only, so they are lost when the server restarts
+type MemoryStore struct {
+	sessions map[string]*Session
+	mu       sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory session store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*Session)}
+}
+
+// Get returns the session with the given ID
+func (m *MemoryStore) Get(id string) (*Session, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.sessions[id]
+	return session, ok
+}
+
+// Put adds a new session; sessions already in the store are up to date, since it holds them
+func (m *MemoryStore) Put(session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+// Delete removes the session with the given ID, if any
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// List returns every session in the store
+func (m *MemoryStore) List() []*Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Load returns no sessions, as nothing outlives the process
+func (m *MemoryStore) Load() ([]*SessionSnapshot, error) {
+	return nil, nil
+}
+
+// FileStore keeps sessions in memory and saves each of them to a JSON file in a directory,
+// so they survive restarts
+type FileStore struct {
+	*MemoryStore
+	dir   string
+	saved map[string][]byte // the data last written for each session, so unchanged sessions are not rewritten
+	mu    sync.Mutex        // serializes writes
+}
+
+// NewFileStore creates a session store that saves sessions in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &FileStore{
+		MemoryStore: NewMemoryStore(),
+		dir:         dir,
+		saved:       make(map[string][]byte),
+	}, nil
+}
+
+// path returns the file a session is saved in
+func (f *FileStore) path(id string) string {
+	return filepath.Join(f.dir, id+".json")
+}
+
+// Put adds a new session or saves the current state of a stored one, writing its file only if it changed
+// A session that cannot be written is not added
+func (f *FileStore) Put(session *Session) error {
+	return f.save(session, session.Snapshot())
+}
+
+// save writes snapshot to the session's file if it changed, and adds the session
+func (f *FileStore) save(session *Session, snapshot *SessionSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode session %s: %w", session.ID, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !bytes.Equal(f.saved[session.ID], data) {
+		if err := writeFileAtomic(f.path(session.ID), data); err != nil {
+			return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+		}
+		f.saved[session.ID] = data
+	}
+	return f.MemoryStore.Put(session)
+}
+
+// Delete removes the session with the given ID and its file
+func (f *FileStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.saved, id)
+	f.MemoryStore.Delete(id)
+	if err := os.Remove(f.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// Load reads every session file in the store's directory
+// Files that cannot be read are skipped and reported in the error
+func (f *FileStore) Load() ([]*SessionSnapshot, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	var snapshots []*SessionSnapshot
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		snapshot := &SessionSnapshot{}
+		if err := json.Unmarshal(data, snapshot); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, errors.Join(errs...)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so a crash mid-write never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openFileManager opens a file store in dir and a manager with the sessions it restores
+func openFileManager(t *testing.T, dir string) (*Manager, int, error) {
+	t.Helper()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	m := NewManager(WithStore(store))
+	restored, err := m.RestoreSessions()
+	return m, restored, err
+}
+
+func TestFileStoreRestoresSessions(t *testing.T) {
+	dir := t.TempDir()
+	m, _, _ := openFileManager(t, dir)
+	id, err := m.CreateSession(WithBestOf(3))
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	session, _ := m.GetSession(id)
+	x, _, _ := session.JoinSession()
+	o, _, _ := session.JoinSession()
+	winTopRow(t, session, PlayerX)
+	if err := session.Rematch(); err != nil {
+		t.Fatalf("Rematch: %v", err)
+	}
+	if err := session.Game.MakeMove(1, 1, PlayerX); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+	if err := m.SaveSessions(); err != nil {
+		t.Fatalf("SaveSessions: %v", err)
+	}
+
+	restored, count, err := openFileManager(t, dir)
+	if err != nil || count != 1 {
+		t.Fatalf("restored %d sessions: %v", count, err)
+	}
+	got, err := restored.GetSession(id)
+	if err != nil {
+		t.Fatalf("session not restored: %v", err)
+	}
+	for token, want := range map[PlayerToken]Player{x: PlayerO, o: PlayerX} {
+		if player, err := got.GetPlayer(token); err != nil || player != want {
+			t.Errorf("token of %s resolves to %q, %v", want, player, err)
+		}
+	}
+	state := got.Game.GetState()
+	if len(state.Moves) != 1 || state.Board[1][1] != PlayerX || state.Turn != PlayerO {
+		t.Errorf("restored game has moves %v and turn %s, want X's opening move and O to play", state.Moves, state.Turn)
+	}
+	if want := "Series (best of 3): X 0 - O 1"; got.Series().String() != want {
+		t.Errorf("restored series reads %q, want %q", got.Series().String(), want)
+	}
+	if replay, err := got.Replay(0, ""); err != nil || replay.Total != 1 {
+		t.Errorf("replay of the restored game: %+v, %v", replay, err)
+	}
+}
+
+func TestFileStoreDeletesSessions(t *testing.T) {
+	dir := t.TempDir()
+	m, _, _ := openFileManager(t, dir)
+	id, err := m.CreateSession()
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, id+".json")); err != nil {
+		t.Fatalf("new session was not saved: %v", err)
+	}
+	if err := m.DeleteSession(id); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, id+".json")); !os.IsNotExist(err) {
+		t.Errorf("deleted session's file is still there: %v", err)
+	}
+	if _, count, _ := openFileManager(t, dir); count != 0 {
+		t.Errorf("restored %d sessions after the only one was deleted", count)
+	}
+}
+
+func TestFileStoreSkipsUnreadableFiles(t *testing.T) {
+	dir := t.TempDir()
+	m, _, _ := openFileManager(t, dir)
+	id, err := m.CreateSession()
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"id":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, count, err := openFileManager(t, dir)
+	if err == nil {
+		t.Error("unreadable session file was not reported")
+	}
+	if count != 1 {
+		t.Errorf("restored %d sessions, want the readable one", count)
+	}
+	if _, err := restored.GetSession(id); err != nil {
+		t.Errorf("readable session not restored: %v", err)
+	}
+}
+
+func TestRestoreDoesNotChargeDowntime(t *testing.T) {
+	session, _, _ := newStartedSession(t, WithTimeControl(time.Minute))
+	if err := session.Game.MakeMove(1, 1, PlayerX); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+	// The session was saved an hour before the server came back
+	snapshot := session.Snapshot()
+	downSince := time.Now().Add(-time.Hour)
+	snapshot.State.TurnStarted = downSince
+	snapshot.State.Clock.TurnStarted = downSince
+
+	restored, err := NewManager().restoreSession(snapshot)
+	if err != nil {
+		t.Fatalf("restoreSession: %v", err)
+	}
+	state := restored.Game.GetState()
+	if state.Status != StatusPlaying {
+		t.Fatalf("restored game is %s, want O still playing", state.Status)
+	}
+	if remaining := state.Clock.RemainingMs[PlayerO]; remaining < (59 * time.Second).Milliseconds() {
+		t.Errorf("O has %dms left after the restart, want the downtime not charged", remaining)
+	}
+	if time.Since(state.TurnStarted) > time.Minute {
+		t.Errorf("O's turn started at %v, want the restore time", state.TurnStarted)
+	}
+}
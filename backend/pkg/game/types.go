@@ -1,9 +1,25 @@
 package game
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // ManagerConfig holds configuration for the session manager
 type ManagerConfig struct {
 	SessionIDLength   int
 	PlayerTokenLength int
+	// Hooks receive the events of every session, see WithHook
+	Hooks []Hook
+	// VotingWindow is how long crowd-play voting rounds stay open unless the session sets its own window
+	VotingWindow time.Duration
+	// IdleTimeout is how long a session may go without activity before it expires, 0 to keep sessions forever
+	IdleTimeout time.Duration
+	// FinishedGracePeriod is the shorter idle time after which a session whose game is over expires, 0 for IdleTimeout
+	FinishedGracePeriod time.Duration
+	// Store keeps the sessions, nil for an in-memory store, see WithStore
+	Store SessionStore
 }
 
 // ManagerOption is a function that configures a ManagerConfig
@@ -23,12 +39,190 @@ func WithPlayerTokenLength(length int) ManagerOption {
 	}
 }
 
+// WithVotingWindow sets how long crowd-play voting rounds stay open by default
+func WithVotingWindow(window time.Duration) ManagerOption {
+	return func(c *ManagerConfig) {
+		c.VotingWindow = window
+	}
+}
+
+// WithIdleTimeout expires sessions that have seen no join, move or board view for timeout
+func WithIdleTimeout(timeout time.Duration) ManagerOption {
+	return func(c *ManagerConfig) {
+		c.IdleTimeout = timeout
+	}
+}
+
+// WithFinishedGracePeriod expires sessions whose game is over after grace without activity
+func WithFinishedGracePeriod(grace time.Duration) ManagerOption {
+	return func(c *ManagerConfig) {
+		c.FinishedGracePeriod = grace
+	}
+}
+
+// Variant identifies which game a session plays
+type Variant string
+
+const (
+	VariantTicTacToe   Variant = "tictactoe"
+	VariantConnectFour Variant = "connect4"
+	VariantUltimate    Variant = "ultimate"
+	VariantQuantum     Variant = "quantum"
+	VariantNotakto     Variant = "notakto"
+	VariantFog         Variant = "fog"
+)
+
+// InactivityAction is what the server does when a player lets their turn run past the inactivity limit
+type InactivityAction string
+
+const (
+	// InactivityForfeit ends the game with a loss for the inactive player; in games with more than two players,
+	// which cannot be forfeited, a random move is played instead
+	InactivityForfeit InactivityAction = "forfeit"
+	// InactivityRandomMove plays a random legal move on the inactive player's behalf
+	InactivityRandomMove InactivityAction = "random"
+)
+
+// ParseInactivityAction parses an inactivity action ("forfeit" or "random")
+func ParseInactivityAction(action string) (InactivityAction, error) {
+	switch InactivityAction(strings.ToLower(strings.TrimSpace(action))) {
+	case InactivityForfeit, "":
+		return InactivityForfeit, nil
+	case InactivityRandomMove:
+		return InactivityRandomMove, nil
+	default:
+		return "", fmt.Errorf("invalid inactivity action %q (expected forfeit or random)", action)
+	}
+}
+
+// SessionConfig holds configuration for a single game session, chosen at creation time
+type SessionConfig struct {
+	Variant Variant `json:"variant"`
+	// Board overrides the variant's default board when set
+	Board BoardConfig `json:"board"`
+	// Bot seats a server-side opponent as O when set
+	Bot Difficulty `json:"bot,omitempty"`
+	// BestOf plays the session as a best-of-N series, 0 for an open-ended series of rematches
+	BestOf int `json:"best_of,omitempty"`
+	// TimeControl gives each player a clock with this much time per game, 0 for untimed games
+	TimeControl time.Duration `json:"time_control,omitempty"`
+	// StartPosition sets up the board from a position or move list in compact notation, empty for the empty board
+	StartPosition string `json:"start_position,omitempty"`
+	// FirstMove decides who moves first in each game (a start position keeps its own side to move)
+	FirstMove FirstMove `json:"first_move"`
+	// Misere makes completing a line lose instead of win
+	Misere bool `json:"misere,omitempty"`
+	// Boards is the number of boards in a Notakto game, 0 for the default
+	Boards int `json:"boards,omitempty"`
+	// Players is the number of seats, 0 for the usual two
+	Players int `json:"players,omitempty"`
+	// Symbols are the symbols of the seats after X and O in games with more than two players, nil for Δ and □
+	Symbols []Player `json:"symbols,omitempty"`
+	// Crowd lets the audience vote on every move instead of seating players
+	Crowd bool `json:"crowd,omitempty"`
+	// VotingWindow is how long a crowd-play voting round stays open after its first vote, 0 for the manager's default
+	VotingWindow time.Duration `json:"voting_window,omitempty"`
+}
+
+// WithBot seats a bot opponent of the given difficulty in the second seat
+func WithBot(difficulty Difficulty) SessionOption {
+	return func(c *SessionConfig) {
+		c.Bot = difficulty
+	}
+}
+
+// WithVariant sets the game variant played in the session
+func WithVariant(variant Variant) SessionOption {
+	return func(c *SessionConfig) {
+		c.Variant = variant
+	}
+}
+
+// SessionOption is a function that configures a SessionConfig
+type SessionOption func(*SessionConfig)
+
+// WithTimeControl gives each player a clock with budget of thinking time per game
+func WithTimeControl(budget time.Duration) SessionOption {
+	return func(c *SessionConfig) {
+		c.TimeControl = budget
+	}
+}
+
+// WithMisere plays the misère rules: whoever completes a line loses
+func WithMisere() SessionOption {
+	return func(c *SessionConfig) {
+		c.Misere = true
+	}
+}
+
+// WithBoards sets the number of boards in a Notakto game
+func WithBoards(n int) SessionOption {
+	return func(c *SessionConfig) {
+		c.Boards = n
+	}
+}
+
+// WithPlayers sets the number of seats (2-4); the game starts once all of them are filled
+func WithPlayers(n int) SessionOption {
+	return func(c *SessionConfig) {
+		c.Players = n
+	}
+}
+
+// WithSymbols sets the symbols of the seats after X and O in a game with more than two players,
+// one per extra seat, e.g. WithPlayers(3) and WithSymbols("A")
+func WithSymbols(symbols ...Player) SessionOption {
+	return func(c *SessionConfig) {
+		c.Symbols = symbols
+	}
+}
+
+// WithCrowd plays the session as "the audience vs. the audience": teams X and O vote on their moves,
+// and the most-voted move is played once window has passed since the round's first vote (0 for the default)
+func WithCrowd(window time.Duration) SessionOption {
+	return func(c *SessionConfig) {
+		c.Crowd = true
+		c.VotingWindow = window
+	}
+}
+
+// WithFirstMove sets the rule that decides who moves first in each game
+func WithFirstMove(rule FirstMove) SessionOption {
+	return func(c *SessionConfig) {
+		c.FirstMove = rule
+	}
+}
+
+// WithStartPosition sets up the board from a position (e.g. "XO_X_O___:O") or a move list (e.g. "b2 a1 c3")
+func WithStartPosition(notation string) SessionOption {
+	return func(c *SessionConfig) {
+		c.StartPosition = notation
+	}
+}
+
+// WithBestOf plays the session as a series that ends once a player wins more than half of n games
+func WithBestOf(n int) SessionOption {
+	return func(c *SessionConfig) {
+		c.BestOf = n
+	}
+}
+
+// WithBoard sets the board dimensions and the number of marks in a row needed to win
+func WithBoard(rows, cols, winLength int) SessionOption {
+	return func(c *SessionConfig) {
+		c.Board = BoardConfig{Rows: rows, Cols: cols, WinLength: winLength}
+	}
+}
+
 // Player represents a tic-tac-toe player
 type Player string
 
 const (
 	PlayerX Player = "X"
 	PlayerO Player = "O"
+	// PlayerTriangle and PlayerSquare take the third and fourth seats of games with more than two players
+	PlayerTriangle Player = "Δ"
+	PlayerSquare   Player = "□"
 )
 
 // Status represents the current game status
@@ -39,12 +233,230 @@ const (
 	StatusPlaying Status = "playing"
 	StatusXWins   Status = "X_wins"
 	StatusOWins   Status = "O_wins"
-	StatusDraw    Status = "draw"
+	// StatusTriangleWins and StatusSquareWins end a game with more than two players
+	StatusTriangleWins Status = "Δ_wins"
+	StatusSquareWins   Status = "□_wins"
+	StatusDraw         Status = "draw"
+	// StatusXResigned and StatusOResigned end the game with a win for the player who did not resign
+	StatusXResigned Status = "X_resigned"
+	StatusOResigned Status = "O_resigned"
+	// StatusDrawAgreed ends the game with a draw both players agreed to
+	StatusDrawAgreed Status = "draw_agreed"
+	// StatusXTimeout and StatusOTimeout end the game with a loss for the player whose clock ran out
+	StatusXTimeout Status = "X_timeout"
+	StatusOTimeout Status = "O_timeout"
+	// StatusXForfeited and StatusOForfeited end the game with a loss for the player who forfeited it
+	StatusXForfeited Status = "X_forfeited"
+	StatusOForfeited Status = "O_forfeited"
+	// StatusXCompletedLine and StatusOCompletedLine end a misère or Notakto game with a loss
+	// for the player who completed the (last) line
+	StatusXCompletedLine Status = "X_completed_line"
+	StatusOCompletedLine Status = "O_completed_line"
+)
+
+// Winner returns the player who won a finished game, or "" for draws and unfinished games
+func (s Status) Winner() Player {
+	switch s {
+	case StatusXWins, StatusOResigned, StatusOTimeout, StatusOForfeited, StatusOCompletedLine:
+		return PlayerX
+	case StatusOWins, StatusXResigned, StatusXTimeout, StatusXForfeited, StatusXCompletedLine:
+		return PlayerO
+	default:
+		// The wins of the other seats, whose symbols can be set at creation (see WithSymbols)
+		if player, ok := strings.CutSuffix(string(s), "_wins"); ok {
+			return Player(player)
+		}
+		return ""
+	}
+}
+
+// IsOver reports whether the status ends the game
+func (s Status) IsOver() bool {
+	return s != StatusPending && s != StatusPlaying
+}
+
+// Board size limits for m,n,k games
+const (
+	MinBoardSize = 3
+	MaxBoardSize = 19
 )
 
+// BoardConfig describes an m,n,k board: Rows x Cols cells, WinLength marks in a row to win
+type BoardConfig struct {
+	Rows      int `json:"rows"`
+	Cols      int `json:"cols"`
+	WinLength int `json:"win_length"`
+}
+
+// DefaultBoardConfig is the classic 3x3, three-in-a-row board
+var DefaultBoardConfig = BoardConfig{Rows: 3, Cols: 3, WinLength: 3}
+
+// Validate checks that the board dimensions and win length are playable
+func (c BoardConfig) Validate() error {
+	if c.Rows < MinBoardSize || c.Rows > MaxBoardSize || c.Cols < MinBoardSize || c.Cols > MaxBoardSize {
+		return NewInvalidBoardError(c, fmt.Sprintf("rows and cols must be %d-%d", MinBoardSize, MaxBoardSize))
+	}
+	longest := c.Rows
+	if c.Cols > longest {
+		longest = c.Cols
+	}
+	if c.WinLength < MinBoardSize || c.WinLength > longest {
+		return NewInvalidBoardError(c, fmt.Sprintf("win length must be %d-%d", MinBoardSize, longest))
+	}
+	return nil
+}
+
+// String returns the board in RxC-K notation (e.g., 15x15-5)
+func (c BoardConfig) String() string {
+	return fmt.Sprintf("%dx%d-%d", c.Rows, c.Cols, c.WinLength)
+}
+
+// Position identifies a cell on the board
+type Position struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// String returns the position as ROW-COL
+func (p Position) String() string {
+	return fmt.Sprintf("%d-%d", p.Row, p.Col)
+}
+
+// Move records an accepted move
+type Move struct {
+	Number    int       `json:"number"`
+	Player    Player    `json:"player"`
+	Row       int       `json:"row"`
+	Col       int       `json:"col"`
+	Timestamp time.Time `json:"timestamp"`
+	// Reason explains why the server played the move on the player's behalf, empty for moves the player made
+	Reason string `json:"reason,omitempty"`
+	// Second is the other cell of a quantum tic-tac-toe spooky mark, nil for classical moves
+	Second *Position `json:"second,omitempty"`
+	// CollapsedTo is the cell a spooky mark that closed a cycle was collapsed into, nil otherwise
+	CollapsedTo *Position `json:"collapsed_to,omitempty"`
+	// Blocked is set when a fog-of-war move ran into a hidden opponent mark: nothing was placed
+	Blocked bool `json:"blocked,omitempty"`
+	// Hidden is set when the move's cell is withheld from the viewer (Row and Col are -1)
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// String returns the move as "N. PLAYER ROW-COL" ("N. PLAYER ROW-COL+ROW-COL" for spooky marks)
+func (m Move) String() string {
+	if m.Hidden {
+		return fmt.Sprintf("%d. %s ?", m.Number, m.Player)
+	}
+	text := fmt.Sprintf("%d. %s %d-%d", m.Number, m.Player, m.Row, m.Col)
+	if m.Blocked {
+		text += " (blocked)"
+	}
+	if m.Second != nil {
+		text += "+" + m.Second.String()
+	}
+	if m.CollapsedTo != nil {
+		text += " (collapsed to " + m.CollapsedTo.String() + ")"
+	}
+	return text
+}
+
 // GameState represents the current state of a tic-tac-toe game
 type GameState struct {
-	Board  [3][3]Player `json:"board"`
-	Turn   Player       `json:"turn"`
-	Status Status       `json:"status"`
+	Variant   Variant    `json:"variant"`
+	Board     [][]Player `json:"board"`
+	Rows      int        `json:"rows"`
+	Cols      int        `json:"cols"`
+	WinLength int        `json:"win_length"`
+	Turn      Player     `json:"turn"`
+	Status    Status     `json:"status"`
+	Moves     []Move     `json:"moves"`
+	// FirstPlayer is the player who moves first in this game
+	FirstPlayer Player `json:"first_player"`
+	// Players is the turn order of a game with more than two players, nil for X and O
+	Players []Player `json:"players,omitempty"`
+	// WinningLine holds the cells of the line that decided the game, nil until someone wins
+	// (in misère and Notakto games it is the line that lost; ultimate tic-tac-toe reports
+	// its winning local boards in Ultimate.WinningLine instead)
+	WinningLine []Position `json:"winning_line,omitempty"`
+	// UndoRequest is the player waiting for the opponent to accept a takeback, if any
+	UndoRequest Player `json:"undo_request,omitempty"`
+	// DrawOffer is the player waiting for the opponent to accept a draw, if any
+	DrawOffer Player `json:"draw_offer,omitempty"`
+	// TurnStarted is when the player to move's turn began, zero until the game starts
+	TurnStarted time.Time `json:"turn_started"`
+	// EndReason explains how a game that was not decided on the board ended, e.g. an inactivity forfeit
+	EndReason string `json:"end_reason,omitempty"`
+	// Clock holds each player's remaining time in a timed game, nil for untimed games
+	Clock *ClockState `json:"clock,omitempty"`
+	// Misere is set when completing a line loses the game
+	Misere bool `json:"misere,omitempty"`
+	// Start is the compact notation of the position the game was set up from, empty for the empty board
+	Start string `json:"start,omitempty"`
+
+	// Ultimate holds the extra state of ultimate tic-tac-toe, nil for other variants
+	Ultimate *UltimateState `json:"ultimate,omitempty"`
+	// Quantum holds the spooky marks of quantum tic-tac-toe, nil for other variants
+	Quantum *QuantumState `json:"quantum,omitempty"`
+	// Notakto holds which Notakto boards are dead, nil for other variants
+	Notakto *NotaktoState `json:"notakto,omitempty"`
+	// Fog holds the opponent marks each fog-of-war player has revealed, nil for other variants
+	Fog *FogState `json:"fog,omitempty"`
+}
+
+// UltimateState holds the meta-board of an ultimate tic-tac-toe game
+// Local boards and cells are numbered 0-8, left to right and top to bottom
+type UltimateState struct {
+	// MetaBoard holds the status of each local board (playing, X_wins, O_wins or draw)
+	MetaBoard [][]Status `json:"meta_board"`
+	// LocalBoards holds the 3x3 cells of each local board, indexed by board number
+	LocalBoards [][][]Player `json:"local_boards"`
+	// ForcedBoard is the board the player to move must play in, or -1 for any open board
+	ForcedBoard int `json:"forced_board"`
+	// WinningLine holds the meta-board coordinates of the local boards that won the game, nil until someone wins
+	WinningLine []Position `json:"winning_line,omitempty"`
+}
+
+// Clone returns a deep copy of the game state
+func (s *GameState) Clone() *GameState {
+	stateCopy := *s
+	stateCopy.Board = make([][]Player, len(s.Board))
+	for i, row := range s.Board {
+		stateCopy.Board[i] = append([]Player(nil), row...)
+	}
+	stateCopy.Moves = append([]Move{}, s.Moves...)
+	stateCopy.WinningLine = append([]Position(nil), s.WinningLine...)
+	stateCopy.Players = append([]Player(nil), s.Players...)
+	stateCopy.Clock = s.Clock.clone()
+	if s.Ultimate != nil {
+		ultimateCopy := *s.Ultimate
+		ultimateCopy.MetaBoard = make([][]Status, len(s.Ultimate.MetaBoard))
+		for i, row := range s.Ultimate.MetaBoard {
+			ultimateCopy.MetaBoard[i] = append([]Status(nil), row...)
+		}
+		ultimateCopy.LocalBoards = make([][][]Player, len(s.Ultimate.LocalBoards))
+		for i, local := range s.Ultimate.LocalBoards {
+			ultimateCopy.LocalBoards[i] = make([][]Player, len(local))
+			for j, row := range local {
+				ultimateCopy.LocalBoards[i][j] = append([]Player(nil), row...)
+			}
+		}
+		ultimateCopy.WinningLine = append([]Position(nil), s.Ultimate.WinningLine...)
+		stateCopy.Ultimate = &ultimateCopy
+	}
+	stateCopy.Quantum = s.Quantum.clone()
+	stateCopy.Fog = s.Fog.clone()
+	if s.Notakto != nil {
+		notaktoCopy := *s.Notakto
+		notaktoCopy.Dead = append([]bool(nil), s.Notakto.Dead...)
+		stateCopy.Notakto = &notaktoCopy
+	}
+	return &stateCopy
+}
+
+// newBoard allocates an empty rows x cols board
+func newBoard(rows, cols int) [][]Player {
+	board := make([][]Player, rows)
+	for i := range board {
+		board[i] = make([]Player, cols)
+	}
+	return board
 }
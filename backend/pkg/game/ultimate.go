@@ -0,0 +1,303 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubBoardMover is implemented by engines played on several local boards,
+// where a move names a board and a cell (0-8) within it
+type SubBoardMover interface {
+	// MakeSubBoardMove places the player's mark in the given cell of the given board
+	// Returns an error if the move is invalid
+	MakeSubBoardMove(board, cell int, player Player) error
+}
+
+// UltimateTicTacToe implements the Engine interface for ultimate tic-tac-toe
+// Nine local 3x3 boards form a 3x3 meta-board. The cell a player picks decides which
+// local board the opponent must play in next, and three local boards in a row win the game.
+// Board holds all 81 cells as a 9x9 grid, so MakeMove takes global coordinates (0-8).
+type UltimateTicTacToe struct {
+	baseEngine
+}
+
+// NewUltimateTicTacToe creates a new ultimate tic-tac-toe game instance
+func NewUltimateTicTacToe() *UltimateTicTacToe {
+	g := &UltimateTicTacToe{}
+	g.state = g.newState()
+	return g
+}
+
+// newState returns an empty, pending ultimate state
+func (g *UltimateTicTacToe) newState() *GameState {
+	metaBoard := make([][]Status, 3)
+	for i := range metaBoard {
+		metaBoard[i] = []Status{StatusPlaying, StatusPlaying, StatusPlaying}
+	}
+	localBoards := make([][][]Player, 9)
+	for i := range localBoards {
+		localBoards[i] = newBoard(3, 3)
+	}
+	return &GameState{
+		Variant:     VariantUltimate,
+		Board:       newBoard(9, 9),
+		Rows:        9,
+		Cols:        9,
+		WinLength:   3,
+		Turn:        PlayerX,
+		FirstPlayer: PlayerX,
+		Status:      StatusPending,
+		Ultimate: &UltimateState{
+			MetaBoard:   metaBoard,
+			LocalBoards: localBoards,
+			ForcedBoard: -1,
+		},
+	}
+}
+
+// UltimateCell converts a local board number and cell number (both 0-8) to global 9x9 coordinates
+func UltimateCell(board, cell int) (row, col int) {
+	return (board/3)*3 + cell/3, (board%3)*3 + cell%3
+}
+
+// UltimateBoardCell converts global 9x9 coordinates to a local board number and cell number
+func UltimateBoardCell(row, col int) (board, cell int) {
+	return (row/3)*3 + col/3, (row%3)*3 + col%3
+}
+
+// MakeMove attempts to make a move at the specified global position (0-8, 0-8)
+func (g *UltimateTicTacToe) MakeMove(row, col int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+
+	if row < 0 || row >= 9 || col < 0 || col >= 9 {
+		return NewInvalidPositionError(row, col, 9, 9)
+	}
+
+	board, cell := UltimateBoardCell(row, col)
+	return g.placeMark(board, cell, player)
+}
+
+// MakeSubBoardMove places the player's mark in cell (0-8) of local board (0-8)
+func (g *UltimateTicTacToe) MakeSubBoardMove(board, cell int, player Player) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkCanMove(player); err != nil {
+		return err
+	}
+
+	if board < 0 || board >= 9 || cell < 0 || cell >= 9 {
+		return NewInvalidSubBoardMoveError(board, cell, 9)
+	}
+
+	return g.placeMark(board, cell, player)
+}
+
+// placeMark places player's mark in cell of board, then updates the meta-board, status, forced board and turn
+// Must be called with the lock held, after checkCanMove
+func (g *UltimateTicTacToe) placeMark(board, cell int, player Player) error {
+	ultimate := g.state.Ultimate
+	if ultimate.ForcedBoard >= 0 && board != ultimate.ForcedBoard {
+		return NewWrongBoardError(board, ultimate.ForcedBoard)
+	}
+
+	if ultimate.MetaBoard[board/3][board%3] != StatusPlaying {
+		return NewBoardClosedError(board, ultimate.MetaBoard[board/3][board%3])
+	}
+
+	local := ultimate.LocalBoards[board]
+	if local[cell/3][cell%3] != "" {
+		return ErrPositionTaken
+	}
+
+	row, col := UltimateCell(board, cell)
+	g.state.Board[row][col] = player
+	local[cell/3][cell%3] = player
+	g.recordMove(row, col, player)
+
+	// Settle the local board
+	if hasLine(local, player, 3) {
+		ultimate.MetaBoard[board/3][board%3] = winStatus(player)
+	} else if isFull(local) {
+		ultimate.MetaBoard[board/3][board%3] = StatusDraw
+	}
+
+	// Settle the game on the meta-board
+	if line := g.metaLine(player); line != nil {
+		ultimate.WinningLine = line
+		g.endGame(winStatus(player))
+		return nil
+	}
+	if g.isMetaBoardDecided() {
+		g.endGame(StatusDraw)
+		return nil
+	}
+
+	// The cell picked sends the opponent to the matching board, unless that board is already decided
+	ultimate.ForcedBoard = cell
+	if ultimate.MetaBoard[cell/3][cell%3] != StatusPlaying {
+		ultimate.ForcedBoard = -1
+	}
+
+	g.switchTurn()
+	return nil
+}
+
+// metaLine returns the meta-board coordinates of three local boards in a row won by player, or nil
+// Must be called with the lock held
+func (g *UltimateTicTacToe) metaLine(player Player) []Position {
+	won := winStatus(player)
+	meta := newBoard(3, 3)
+	for i, row := range g.state.Ultimate.MetaBoard {
+		for j, status := range row {
+			if status == won {
+				meta[i][j] = player
+			}
+		}
+	}
+	return findLine(meta, player, 3)
+}
+
+// isMetaBoardDecided reports whether every local board has been won or drawn
+// Must be called with the lock held
+func (g *UltimateTicTacToe) isMetaBoardDecided() bool {
+	for _, row := range g.state.Ultimate.MetaBoard {
+		for _, status := range row {
+			if status == StatusPlaying {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AcceptUndo accepts the opponent's pending takeback request and returns the number of moves undone
+func (g *UltimateTicTacToe) AcceptUndo(player Player) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.acceptUndo(g, player)
+}
+
+// replayMove applies a recorded move (in global coordinates) to the current state
+// Must be called with the lock held
+func (g *UltimateTicTacToe) replayMove(move Move) error {
+	board, cell := UltimateBoardCell(move.Row, move.Col)
+	return g.placeMark(board, cell, move.Player)
+}
+
+// legalMoves returns every empty cell of the forced board, or of every open board when play is free
+func (g *UltimateTicTacToe) legalMoves() []Position {
+	if g.state.Status != StatusPlaying {
+		return nil
+	}
+	ultimate := g.state.Ultimate
+	var moves []Position
+	for board, local := range ultimate.LocalBoards {
+		if ultimate.ForcedBoard >= 0 && board != ultimate.ForcedBoard {
+			continue
+		}
+		if ultimate.MetaBoard[board/3][board%3] != StatusPlaying {
+			continue
+		}
+		for cell := 0; cell < 9; cell++ {
+			if local[cell/3][cell%3] == "" {
+				row, col := UltimateCell(board, cell)
+				moves = append(moves, Position{Row: row, Col: col})
+			}
+		}
+	}
+	return moves
+}
+
+// snapshot returns an independent copy of the game
+func (g *UltimateTicTacToe) snapshot() searchable {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return &UltimateTicTacToe{baseEngine: baseEngine{state: g.cloneForSearch(), firstPlayer: g.firstPlayer}}
+}
+
+// Reset resets the game to its initial state
+func (g *UltimateTicTacToe) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// Reset to pending - the caller should call StartGame() if both players are still in
+	g.state = g.initialState(g)
+	g.resetClock()
+	g.emit(Event{Type: EventReset})
+}
+
+// FormatBoard returns a human-readable string representation of the board
+// Local boards are separated by | and - lines, followed by the meta-board and the board to play next
+func (g *UltimateTicTacToe) FormatBoard() string {
+	state := g.GetState()
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for i, row := range state.Board {
+		if i > 0 && i%3 == 0 {
+			sb.WriteString("------+-------+------\n")
+		}
+		for j, cell := range row {
+			if j > 0 && j%3 == 0 {
+				sb.WriteString(" |")
+			}
+			if j > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(cellSymbol(cell, true))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Once the game is won, boards off the winning line are shown in lower case
+	winningLine := state.Ultimate.WinningLine
+	sb.WriteString("Meta board:\n")
+	for i, row := range state.Ultimate.MetaBoard {
+		for j, status := range row {
+			if j > 0 {
+				sb.WriteString(" ")
+			}
+			symbol := metaSymbol(status)
+			if winningLine != nil && !onLine(winningLine, i, j) {
+				symbol = strings.ToLower(symbol)
+			}
+			sb.WriteString(symbol)
+		}
+		sb.WriteString("\n")
+	}
+	if winningLine != nil {
+		boards := make([]string, len(winningLine))
+		for i, p := range winningLine {
+			boards[i] = fmt.Sprint(p.Row*3 + p.Col)
+		}
+		sb.WriteString(fmt.Sprintf("Winning boards: %s\n", strings.Join(boards, " ")))
+	}
+
+	if state.Status == StatusPlaying {
+		if state.Ultimate.ForcedBoard >= 0 {
+			sb.WriteString(fmt.Sprintf("Next board: %d\n", state.Ultimate.ForcedBoard))
+		} else {
+			sb.WriteString("Next board: any open board\n")
+		}
+	}
+	writeStatusLines(&sb, state)
+	return sb.String()
+}
+
+// metaSymbol returns the meta-board symbol for a local board status: the winner, # for a draw or _ while open
+func metaSymbol(status Status) string {
+	switch status {
+	case StatusXWins:
+		return string(PlayerX)
+	case StatusOWins:
+		return string(PlayerO)
+	case StatusDraw:
+		return "#"
+	default:
+		return "_"
+	}
+}